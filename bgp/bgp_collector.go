@@ -49,17 +49,10 @@ func (*bgpCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect collects metrics from Cisco
 func (c *bgpCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	out, err := client.RunCommand("show bgp all summary")
+	items, err := c.sessions(client, labelValues)
 	if err != nil {
 		return err
 	}
-	items, err := c.Parse(client.OSType, out)
-	if err != nil {
-		if client.Debug {
-			log.Printf("Parse bgp sessions for %s: %s\n", labelValues[0], err.Error())
-		}
-		return nil
-	}
 
 	for _, item := range items {
 		l := append(labelValues, item.Asn, item.IP)
@@ -77,3 +70,41 @@ func (c *bgpCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric,
 
 	return nil
 }
+
+func (c *bgpCollector) sessions(client *rpc.Client, labelValues []string) ([]BgpSession, error) {
+	if supportsGNMI(client) {
+		return c.sessionsGNMI(client)
+	}
+
+	if supportsNetconf(client) {
+		return c.sessionsNetconf(client)
+	}
+
+	if supportsRestconf(client) {
+		return c.sessionsRestconf(client)
+	}
+
+	if client.OSType == rpc.NXOS && client.NXAPI != nil {
+		return c.sessionsNXAPI(client)
+	}
+
+	cmd := "show bgp all summary"
+	if client.OSType == rpc.IOSXR {
+		// IOS XR expects both the address family and the sub address family
+		cmd = "show bgp all all summary"
+	}
+
+	out, err := client.RunCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.Parse(client.OSType, out)
+	if err != nil {
+		if client.Debug {
+			log.Printf("Parse bgp sessions for %s: %s\n", labelValues[0], err.Error())
+		}
+		return nil, nil
+	}
+
+	return items, nil
+}
@@ -0,0 +1,48 @@
+package bgp
+
+import (
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const openconfigNetworkInstanceModel = "openconfig-network-instance"
+
+type gnmiNetworkInstances struct {
+	NetworkInstances struct {
+		Instances []struct {
+			Protocols struct {
+				Protocols []struct {
+					BGP struct {
+						Neighbors struct {
+							Neighbors []openconfigNeighbor `json:"neighbor"`
+						} `json:"neighbors"`
+					} `json:"bgp"`
+				} `json:"protocol"`
+			} `json:"protocols"`
+		} `json:"network-instance"`
+	} `json:"network-instances"`
+}
+
+// supportsGNMI checks if the BGP sessions can be retrieved using gNMI
+func supportsGNMI(client *rpc.Client) bool {
+	return client.HasGNMIModel(openconfigNetworkInstanceModel)
+}
+
+// sessionsGNMI retrieves the BGP sessions of all network instances using openconfig-network-instance
+func (c *bgpCollector) sessionsGNMI(client *rpc.Client) ([]BgpSession, error) {
+	data := &gnmiNetworkInstances{}
+	err := client.GNMIGet("/network-instances/network-instance/protocols/protocol/bgp/neighbors", data)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []BgpSession{}
+	for _, instance := range data.NetworkInstances.Instances {
+		for _, protocol := range instance.Protocols.Protocols {
+			for _, n := range protocol.BGP.Neighbors.Neighbors {
+				items = append(items, sessionFromOpenconfig(n))
+			}
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,93 @@
+package bgp
+
+import (
+	"encoding/json"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const (
+	openconfigBGPNamespace             = "http://openconfig.net/yang/bgp"
+	openconfigNetworkInstanceNamespace = "http://openconfig.net/yang/network-instance"
+)
+
+type openconfigNetworkInstances struct {
+	Instances []struct {
+		Protocols []struct {
+			Neighbors []openconfigNeighbor `xml:"bgp>neighbors>neighbor"`
+		} `xml:"protocols>protocol"`
+	} `xml:"network-instances>network-instance"`
+}
+
+// openconfigNeighbor is a BGP neighbor of openconfig-bgp, which is used by NETCONF and gNMI
+type openconfigNeighbor struct {
+	State struct {
+		NeighborAddress string      `xml:"neighbor-address" json:"neighbor-address"`
+		PeerAS          json.Number `xml:"peer-as" json:"peer-as"`
+		SessionState    string      `xml:"session-state" json:"session-state"`
+		Messages        struct {
+			Sent     openconfigMessages `xml:"sent" json:"sent"`
+			Received openconfigMessages `xml:"received" json:"received"`
+		} `xml:"messages" json:"messages"`
+	} `xml:"state" json:"state"`
+	AfiSafis struct {
+		AfiSafi []struct {
+			State struct {
+				Prefixes struct {
+					Received rpc.JSONFloat `xml:"received" json:"received"`
+				} `xml:"prefixes" json:"prefixes"`
+			} `xml:"state" json:"state"`
+		} `xml:"afi-safi" json:"afi-safi"`
+	} `xml:"afi-safis" json:"afi-safis"`
+}
+
+type openconfigMessages struct {
+	Update       rpc.JSONFloat `xml:"UPDATE" json:"UPDATE"`
+	Notification rpc.JSONFloat `xml:"NOTIFICATION" json:"NOTIFICATION"`
+}
+
+// supportsNetconf checks if the BGP sessions can be retrieved using NETCONF
+func supportsNetconf(client *rpc.Client) bool {
+	return client.HasNetconfCapability(openconfigNetworkInstanceNamespace) && client.HasNetconfCapability(openconfigBGPNamespace)
+}
+
+// sessionsNetconf retrieves the BGP sessions of all network instances using openconfig-bgp
+func (c *bgpCollector) sessionsNetconf(client *rpc.Client) ([]BgpSession, error) {
+	filter := `<network-instances xmlns="` + openconfigNetworkInstanceNamespace + `"><network-instance><protocols><protocol>` +
+		`<bgp><neighbors/></bgp>` +
+		`</protocol></protocols></network-instance></network-instances>`
+
+	data := &openconfigNetworkInstances{}
+	err := client.NetconfGet(filter, data)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []BgpSession{}
+	for _, instance := range data.Instances {
+		for _, protocol := range instance.Protocols {
+			for _, n := range protocol.Neighbors {
+				items = append(items, sessionFromOpenconfig(n))
+			}
+		}
+	}
+
+	return items, nil
+}
+
+func sessionFromOpenconfig(n openconfigNeighbor) BgpSession {
+	msgs := n.State.Messages
+	s := BgpSession{
+		IP:             n.State.NeighborAddress,
+		Asn:            n.State.PeerAS.String(),
+		Up:             n.State.SessionState == "ESTABLISHED",
+		InputMessages:  float64(msgs.Received.Update + msgs.Received.Notification),
+		OutputMessages: float64(msgs.Sent.Update + msgs.Sent.Notification),
+	}
+
+	for _, a := range n.AfiSafis.AfiSafi {
+		s.ReceivedPrefixes += float64(a.State.Prefixes.Received)
+	}
+
+	return s
+}
@@ -0,0 +1,99 @@
+package bgp
+
+import (
+	"encoding/json"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+type nxapiSummary struct {
+	VRFs struct {
+		Rows json.RawMessage `json:"ROW_vrf"`
+	} `json:"TABLE_vrf"`
+}
+
+type nxapiVRF struct {
+	AddressFamilies struct {
+		Rows json.RawMessage `json:"ROW_af"`
+	} `json:"TABLE_af"`
+}
+
+type nxapiAddressFamily struct {
+	SubsequentAddressFamilies struct {
+		Rows json.RawMessage `json:"ROW_saf"`
+	} `json:"TABLE_saf"`
+}
+
+type nxapiSubsequentAddressFamily struct {
+	Neighbors struct {
+		Rows json.RawMessage `json:"ROW_neighbor"`
+	} `json:"TABLE_neighbor"`
+}
+
+type nxapiNeighbor struct {
+	IP             string        `json:"neighborid"`
+	Asn            string        `json:"neighboras"`
+	State          string        `json:"state"`
+	MessagesIn     rpc.JSONFloat `json:"msgrecvd"`
+	MessagesOut    rpc.JSONFloat `json:"msgsent"`
+	PrefixReceived rpc.JSONFloat `json:"prefixreceived"`
+}
+
+// sessionsNXAPI retrieves the sessions of all VRFs and address families using the structured output of NX-API
+func (c *bgpCollector) sessionsNXAPI(client *rpc.Client) ([]BgpSession, error) {
+	data := &nxapiSummary{}
+	err := client.RunCommandJSON("show bgp all summary", data)
+	if err != nil {
+		return nil, err
+	}
+
+	vrfs := []nxapiVRF{}
+	err = rpc.UnmarshalRows(data.VRFs.Rows, &vrfs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []BgpSession{}
+	for _, vrf := range vrfs {
+		afs := []nxapiAddressFamily{}
+		err = rpc.UnmarshalRows(vrf.AddressFamilies.Rows, &afs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, af := range afs {
+			safs := []nxapiSubsequentAddressFamily{}
+			err = rpc.UnmarshalRows(af.SubsequentAddressFamilies.Rows, &safs)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, saf := range safs {
+				neighbors := []nxapiNeighbor{}
+				err = rpc.UnmarshalRows(saf.Neighbors.Rows, &neighbors)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, n := range neighbors {
+					up := n.State == "Established"
+					pref := float64(n.PrefixReceived)
+					if !up {
+						pref = 0
+					}
+
+					items = append(items, BgpSession{
+						IP:               n.IP,
+						Asn:              n.Asn,
+						InputMessages:    float64(n.MessagesIn),
+						OutputMessages:   float64(n.MessagesOut),
+						Up:               up,
+						ReceivedPrefixes: pref,
+					})
+				}
+			}
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,34 @@
+package bgp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+func TestParseIOSXR(t *testing.T) {
+	output, err := os.ReadFile("../cmd/fakecisco/fixtures/iosxr/show_bgp_all_all_summary.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &bgpCollector{}
+	items, err := c.Parse(rpc.IOSXR, string(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []BgpSession{
+		{IP: "10.0.0.2", Asn: "65001", Up: true, ReceivedPrefixes: 850, InputMessages: 123456, OutputMessages: 123460},
+		{IP: "10.0.0.6", Asn: "65002", Up: false, ReceivedPrefixes: 0, InputMessages: 0, OutputMessages: 0},
+		{IP: "2001:db8::2", Asn: "65001", Up: true, ReceivedPrefixes: 120, InputMessages: 55555, OutputMessages: 55560},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d sessions, want %d", len(items), len(want))
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("got %+v, want %+v", items[i], want[i])
+		}
+	}
+}
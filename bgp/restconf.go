@@ -0,0 +1,75 @@
+package bgp
+
+import (
+	"encoding/json"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const bgpOperModule = "Cisco-IOS-XE-bgp-oper"
+
+type restconfMessageCounters struct {
+	Opens         rpc.JSONFloat `json:"opens"`
+	Updates       rpc.JSONFloat `json:"updates"`
+	Notifications rpc.JSONFloat `json:"notifications"`
+	Keepalives    rpc.JSONFloat `json:"keepalives"`
+	RouteRefreshs rpc.JSONFloat `json:"route-refreshes"`
+}
+
+func (m restconfMessageCounters) total() float64 {
+	return float64(m.Opens + m.Updates + m.Notifications + m.Keepalives + m.RouteRefreshs)
+}
+
+type restconfNeighbors struct {
+	Neighbors struct {
+		Neighbors []struct {
+			NeighborID   string      `json:"neighbor-id"`
+			AS           json.Number `json:"as"`
+			SessionState string      `json:"session-state"`
+			Counters     struct {
+				Sent     restconfMessageCounters `json:"sent"`
+				Received restconfMessageCounters `json:"received"`
+			} `json:"bgp-neighbor-counters"`
+			PrefixActivity struct {
+				Received struct {
+					CurrentPrefixes rpc.JSONFloat `json:"current-prefixes"`
+				} `json:"received"`
+			} `json:"prefix-activity"`
+		} `json:"neighbor"`
+	} `json:"Cisco-IOS-XE-bgp-oper:neighbors"`
+}
+
+// supportsRestconf checks if the sessions can be retrieved using RESTCONF
+func supportsRestconf(client *rpc.Client) bool {
+	return client.HasRestconfModule(bgpOperModule)
+}
+
+// sessionsRestconf retrieves the sessions of all address families using the bgp-state-data of Cisco-IOS-XE-bgp-oper.
+// The messages are the sum of all message types, like in the summary shown on the CLI.
+func (c *bgpCollector) sessionsRestconf(client *rpc.Client) ([]BgpSession, error) {
+	data := &restconfNeighbors{}
+	err := client.RestconfGet(bgpOperModule+":bgp-state-data/neighbors", data)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []BgpSession{}
+	for _, n := range data.Neighbors.Neighbors {
+		up := n.SessionState == "fsm-established"
+		pref := float64(n.PrefixActivity.Received.CurrentPrefixes)
+		if !up {
+			pref = 0
+		}
+
+		items = append(items, BgpSession{
+			IP:               n.NeighborID,
+			Asn:              n.AS.String(),
+			InputMessages:    n.Counters.Received.total(),
+			OutputMessages:   n.Counters.Sent.total(),
+			Up:               up,
+			ReceivedPrefixes: pref,
+		})
+	}
+
+	return items, nil
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"regexp"
 	"time"
 
 	"sync"
 
+	"github.com/lwlcom/cisco_exporter/collector"
 	"github.com/lwlcom/cisco_exporter/config"
 	"github.com/lwlcom/cisco_exporter/connector"
 	"github.com/lwlcom/cisco_exporter/dynamiclabels"
 	"github.com/lwlcom/cisco_exporter/rpc"
+	"github.com/lwlcom/cisco_exporter/telemetry"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
@@ -20,34 +25,76 @@ var (
 	scrapeCollectorDurationDesc *prometheus.Desc
 	scrapeDurationDesc          *prometheus.Desc
 	upDesc                      *prometheus.Desc
+	hostKeyMismatchDesc         *prometheus.Desc
+	poolSizeDesc                *prometheus.Desc
+	liveSessionsDesc            *prometheus.Desc
+	reconnectsDesc              *prometheus.Desc
+	collectorSkippedDesc        *prometheus.Desc
+	queueDepthDesc              *prometheus.Desc
+	queueWaitDesc               *prometheus.Desc
+	deviceInfoDesc              *prometheus.Desc
+	deviceUptimeDesc            *prometheus.Desc
+	commandCacheHitsDesc        *prometheus.Desc
+	commandCacheMissesDesc      *prometheus.Desc
+	collectorAgeDesc            *prometheus.Desc
+	pollAgeDesc                 *prometheus.Desc
+	pollPendingDesc             *prometheus.Desc
 )
 
 func init() {
 	upDesc = prometheus.NewDesc(prefix+"up", "Scrape of target was successful", []string{"target"}, nil)
 	scrapeDurationDesc = prometheus.NewDesc(prefix+"collector_duration_seconds", "Duration of a collector scrape for one target", []string{"target"}, nil)
 	scrapeCollectorDurationDesc = prometheus.NewDesc(prefix+"collect_duration_seconds", "Duration of a scrape by collector and target", []string{"target", "collector"}, nil)
+	hostKeyMismatchDesc = prometheus.NewDesc(prefix+"ssh_hostkey_mismatch", "SSH host key of target does not match the known hosts file", []string{"target"}, nil)
+	poolSizeDesc = prometheus.NewDesc(prefix+"ssh_pool_size", "Number of persistent SSH connection slots (one per device and module)", nil, nil)
+	liveSessionsDesc = prometheus.NewDesc(prefix+"ssh_sessions_live", "Number of established persistent SSH connections", nil, nil)
+	collectorSkippedDesc = prometheus.NewDesc(prefix+"collector_skipped", "Collector was skipped or interrupted because the scrape timeout was reached", []string{"target", "collector"}, nil)
+	queueDepthDesc = prometheus.NewDesc(prefix+"session_queue_depth", "Number of scrapes waiting for a free session slot", []string{"limit"}, nil)
+	queueWaitDesc = prometheus.NewDesc(prefix+"session_queue_wait_seconds", "Time the scrape of the target waited for a free session slot", []string{"target"}, nil)
+	reconnectsDesc = prometheus.NewDesc(prefix+"ssh_reconnects_total", "Number of reconnects after a persistent SSH connection dropped", []string{"target"}, nil)
+	deviceInfoDesc = prometheus.NewDesc(prefix+"device_info", "Information about the device found by show version", []string{"target", "os", "hostname", "platform", "serial", "version", "image", "reload_reason", "config_register"}, nil)
+	deviceUptimeDesc = prometheus.NewDesc(prefix+"device_uptime_seconds", "Uptime of the device", []string{"target"}, nil)
+	commandCacheHitsDesc = prometheus.NewDesc(prefix+"command_cache_hits_total", "Number of show commands answered from the command cache", []string{"target"}, nil)
+	collectorAgeDesc = prometheus.NewDesc(prefix+"collect_age_seconds", "Age of the metrics served by collector and target (0 if the collector ran in this scrape)", []string{"target", "collector"}, nil)
+	pollAgeDesc = prometheus.NewDesc(prefix+"poll_age_seconds", "Time since the poll of the target the metrics are from started (only in polling mode)", []string{"target"}, nil)
+	pollPendingDesc = prometheus.NewDesc(prefix+"poll_pending", "The first poll of the target has not completed yet (only in polling mode)", []string{"target"}, nil)
+	commandCacheMissesDesc = prometheus.NewDesc(prefix+"command_cache_misses_total", "Number of show commands run on the device", []string{"target"}, nil)
 }
 
 type ciscoCollector struct {
+	ctx        context.Context
 	devices    []*connector.Device
 	collectors *collectors
+	record     *bool
+	filter     *collectorFilter
+
+	// poller polls the devices in the background, nil if the devices are scraped on each request
+	poller *poller
+
+	// tagMetrics marks the metrics of the collectors with their feature, so polled metrics can be filtered per request
+	tagMetrics bool
 }
 
-func newCiscoCollector(devices []*connector.Device) *ciscoCollector {
+func newCiscoCollector(ctx context.Context, devices []*connector.Device) *ciscoCollector {
 	return &ciscoCollector{
+		ctx:        ctx,
 		devices:    devices,
 		collectors: collectorsForDevices(devices, cfg),
 	}
 }
 
-func deviceInterfaceRegex(cfg *config.Config, host string) *regexp.Regexp {
+func deviceInterfaceRegex(cfg *config.Config, device *connector.Device) *regexp.Regexp {
 	if !cfg.DynamicLabels {
 		return nil
 	}
 
-	dc := cfg.FindDeviceConfig(host)
+	if device.Module != "" && device.DeviceConfig.IfDescReg != nil {
+		return device.DeviceConfig.IfDescReg
+	}
+
+	dc := cfg.FindDeviceConfig(device.Host)
 
-	if dc.IfDescReg != nil {
+	if dc != nil && dc.IfDescReg != nil {
 		return dc.IfDescReg
 	}
 
@@ -63,6 +110,20 @@ func (c *ciscoCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- upDesc
 	ch <- scrapeDurationDesc
 	ch <- scrapeCollectorDurationDesc
+	ch <- hostKeyMismatchDesc
+	ch <- poolSizeDesc
+	ch <- liveSessionsDesc
+	ch <- reconnectsDesc
+	ch <- collectorSkippedDesc
+	ch <- queueDepthDesc
+	ch <- queueWaitDesc
+	ch <- deviceInfoDesc
+	ch <- deviceUptimeDesc
+	ch <- commandCacheHitsDesc
+	ch <- commandCacheMissesDesc
+	ch <- collectorAgeDesc
+	ch <- pollAgeDesc
+	ch <- pollPendingDesc
 
 	for _, col := range c.collectors.allEnabledCollectors() {
 		col.Describe(ch)
@@ -73,12 +134,194 @@ func (c *ciscoCollector) Describe(ch chan<- *prometheus.Desc) {
 func (c *ciscoCollector) Collect(ch chan<- prometheus.Metric) {
 	wg := &sync.WaitGroup{}
 
-	wg.Add(len(c.devices))
 	for _, d := range c.devices {
+		if c.poller != nil && d.Module == "" && c.poller.polls(d.Host) {
+			c.collectPolled(d, ch)
+			continue
+		}
+
+		wg.Add(1)
 		go c.collectForHost(d, ch, wg)
 	}
 
 	wg.Wait()
+
+	if connManager != nil {
+		c.collectConnectionStats(ch)
+	}
+
+	for _, l := range limiters.all() {
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(l.queueDepth()), l.name)
+	}
+}
+
+// collectPolled sends the metrics of the last background poll of device
+func (c *ciscoCollector) collectPolled(device *connector.Device, ch chan<- prometheus.Metric) {
+	res := c.poller.result(device.Host)
+	if res == nil {
+		// not polled yet, so there is nothing to tell about the device except that it is still pending
+		ch <- prometheus.MustNewConstMetric(pollPendingDesc, prometheus.GaugeValue, 1, device.Host)
+		return
+	}
+
+	for _, m := range res.metrics {
+		if tm, ok := m.(*taggedMetric); ok && !c.filter.includes(tm.feature) {
+			continue
+		}
+		ch <- m
+	}
+	ch <- prometheus.MustNewConstMetric(pollAgeDesc, prometheus.GaugeValue, time.Since(res.polledAt).Seconds(), device.Host)
+	ch <- prometheus.MustNewConstMetric(pollPendingDesc, prometheus.GaugeValue, 0, device.Host)
+}
+
+func (c *ciscoCollector) collectConnectionStats(ch chan<- prometheus.Metric) {
+	s := connManager.Stats()
+	ch <- prometheus.MustNewConstMetric(poolSizeDesc, prometheus.GaugeValue, float64(s.PoolSize))
+	ch <- prometheus.MustNewConstMetric(liveSessionsDesc, prometheus.GaugeValue, float64(s.LiveSessions))
+
+	for _, d := range c.devices {
+		if r, found := s.Reconnects[d.Host]; found {
+			ch <- prometheus.MustNewConstMetric(reconnectsDesc, prometheus.CounterValue, float64(r), d.Host)
+		}
+	}
+}
+
+func (c *ciscoCollector) collectDeviceInfo(info *rpc.DeviceInfo, ch chan<- prometheus.Metric, labelValues []string) {
+	if !info.HasDetails() {
+		// identified over NETCONF, gNMI, RESTCONF or telemetry, a series with blank labels would be misleading
+		return
+	}
+
+	l := append(labelValues, info.OSType, info.Hostname, info.Platform, info.Serial, info.Version, info.Image, info.ReloadReason, info.ConfigRegister)
+	ch <- prometheus.MustNewConstMetric(deviceInfoDesc, prometheus.GaugeValue, 1, l...)
+
+	if info.Uptime > 0 {
+		ch <- prometheus.MustNewConstMetric(deviceUptimeDesc, prometheus.GaugeValue, info.UptimeSeconds(), labelValues...)
+	}
+}
+
+// serveResult sends the metrics a collector returned when it ran last, along with the duration of that run.
+// labelValues include the name of the collector.
+func (c *ciscoCollector) serveResult(res *collectorResult, ch chan<- prometheus.Metric, labelValues []string) {
+	for _, m := range res.metrics {
+		ch <- m
+	}
+
+	ch <- prometheus.MustNewConstMetric(scrapeCollectorDurationDesc, prometheus.GaugeValue, res.duration.Seconds(), labelValues...)
+	ch <- prometheus.MustNewConstMetric(collectorSkippedDesc, prometheus.GaugeValue, 0, labelValues...)
+	ch <- prometheus.MustNewConstMetric(collectorAgeDesc, prometheus.GaugeValue, time.Since(res.collectedAt).Seconds(), labelValues...)
+}
+
+func (c *ciscoCollector) collectCommandCacheStats(host string, ch chan<- prometheus.Metric, labelValues []string) {
+	s := commandCache.Stats(host)
+	ch <- prometheus.MustNewConstMetric(commandCacheHitsDesc, prometheus.CounterValue, float64(s.Hits), labelValues...)
+	ch <- prometheus.MustNewConstMetric(commandCacheMissesDesc, prometheus.CounterValue, float64(s.Misses), labelValues...)
+}
+
+func (c *ciscoCollector) connect(device *connector.Device) (connector.Connection, func(), error) {
+	if connManager == nil {
+		conn, err := connector.NewConnection(c.ctx, device, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return conn, conn.Close, nil
+	}
+
+	conn, err := connManager.Connection(c.ctx, device, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, func() { connManager.Release(device) }, nil
+}
+
+// connectNetconf opens a NETCONF session to device. The CLI is connected to when the first command is run.
+// Devices refusing NETCONF are queried using the CLI only.
+func (c *ciscoCollector) connectNetconf(device *connector.Device) (*connector.NetconfSession, connector.Connection, func(), error) {
+	session, err := connector.NewNetconfSession(c.ctx, device, cfg)
+	if errors.Is(err, connector.ErrNetconfNotSupported) {
+		log.Warnf("%s: %v, falling back to CLI", device.Host, err)
+
+		conn, release, err := c.connect(device)
+		return nil, conn, release, err
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	conn := c.lazyConnect(device)
+
+	return session, conn, func() {
+		conn.Close()
+		session.Close()
+	}, nil
+}
+
+// connectRestconf creates a client for the RESTCONF API of device. The CLI is connected to when the first command is run.
+func (c *ciscoCollector) connectRestconf(device *connector.Device) (*connector.RestconfClient, connector.Connection, func(), error) {
+	rc, err := connector.NewRestconfClient(c.ctx, device, cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	conn := c.lazyConnect(device)
+
+	return rc, conn, func() {
+		conn.Close()
+		rc.Close()
+	}, nil
+}
+
+// connectTelemetry returns the telemetry recently streamed by device. The CLI is connected to when the first command is run.
+func (c *ciscoCollector) connectTelemetry(device *connector.Device) (*telemetry.Node, connector.Connection, func(), error) {
+	maxAge := time.Duration(cfg.Telemetry.MaxAge) * time.Second
+	node := telemetryCache.Node(device.TelemetryNode, maxAge)
+	if node == nil {
+		return nil, nil, nil, fmt.Errorf("%s: no telemetry received from node %s within %s", device.Host, device.TelemetryNode, maxAge)
+	}
+
+	conn := c.lazyConnect(device)
+
+	return node, conn, conn.Close, nil
+}
+
+// connectGNMI returns the gNMI session of device, which is kept open across scrapes. The CLI is connected to when the first command is run.
+func (c *ciscoCollector) connectGNMI(device *connector.Device) (*connector.GNMISession, connector.Connection, func(), error) {
+	session, err := gnmiManager.Session(c.ctx, device, cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	conn := c.lazyConnect(device)
+
+	return session, conn, conn.Close, nil
+}
+
+func (c *ciscoCollector) lazyConnect(device *connector.Device) *lazyConnection {
+	return &lazyConnection{
+		host: device.Host,
+		connect: func() (connector.Connection, func(), error) {
+			return c.connect(device)
+		},
+	}
+}
+
+// recordDevice returns if a transcript of the session to device should be written
+func (c *ciscoCollector) recordDevice(device *connector.Device) bool {
+	if recorder == nil {
+		return false
+	}
+
+	if c.record != nil {
+		return *c.record
+	}
+
+	if device.DeviceConfig.Record != nil {
+		return *device.DeviceConfig.Record
+	}
+
+	return cfg.Recording.Enabled
 }
 
 func (c *ciscoCollector) collectForHost(device *connector.Device, ch chan<- prometheus.Metric, wg *sync.WaitGroup) {
@@ -91,31 +334,144 @@ func (c *ciscoCollector) collectForHost(device *connector.Device, ch chan<- prom
 		ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(t).Seconds(), l...)
 	}()
 
-	conn, err := connector.NewSSSHConnection(device, cfg)
+	releaseSlot, wait, err := limiters.acquire(c.ctx, device)
+	ch <- prometheus.MustNewConstMetric(queueWaitDesc, prometheus.GaugeValue, wait.Seconds(), l...)
+	if err != nil {
+		log.Errorln(device.Host + ": " + err.Error())
+		ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, 0, l...)
+		return
+	}
+	defer releaseSlot()
+
+	var session *connector.NetconfSession
+	var restconf *connector.RestconfClient
+	var node *telemetry.Node
+	var gnmi *connector.GNMISession
+	var conn connector.Connection
+	var release func()
+	switch device.Transport {
+	case connector.TransportNetconf:
+		session, conn, release, err = c.connectNetconf(device)
+	case connector.TransportRestconf:
+		restconf, conn, release, err = c.connectRestconf(device)
+	case connector.TransportTelemetry:
+		node, conn, release, err = c.connectTelemetry(device)
+	case connector.TransportGNMI:
+		gnmi, conn, release, err = c.connectGNMI(device)
+	default:
+		conn, release, err = c.connect(device)
+	}
 	if err != nil {
 		log.Errorln(err)
+
+		mismatch := 0
+		var hostKeyErr *connector.HostKeyMismatchError
+		if errors.As(err, &hostKeyErr) {
+			mismatch = 1
+		}
+		ch <- prometheus.MustNewConstMetric(hostKeyMismatchDesc, prometheus.GaugeValue, float64(mismatch), l...)
 		ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, 0, l...)
+
+		// the device might be reloading, so it is identified again when it is reachable
+		deviceInfos.Invalidate(device.Host)
+		commandCache.Invalidate(device.Host)
+		results.invalidate(device.Host)
 		return
 	}
-	defer conn.Close()
+	defer release()
+
+	nxapi, _ := conn.(*connector.NXAPIConnection)
+
+	if c.recordDevice(device) {
+		rec, transcript, err := recorder.Record(conn, device.Host)
+		if err != nil {
+			log.Errorln(device.Host + ": " + err.Error())
+		} else {
+			conn = rec
+			defer transcript.Close()
+		}
+	}
 
+	ch <- prometheus.MustNewConstMetric(hostKeyMismatchDesc, prometheus.GaugeValue, 0, l...)
 	ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, 1, l...)
 
-	client := rpc.NewClient(conn, cfg.Debug)
+	client := rpc.NewClient(c.ctx, conn, cfg.Debug)
+	client.Netconf = session
+	client.NXAPI = nxapi
+	client.Restconf = restconf
+	client.Telemetry = node
+	client.GNMI = gnmi
+	client.Host = device.Host
+	client.CommandCache = commandCache
+	client.Module = device.Module
+	client.Commands = device.DeviceConfig.Commands
+	client.Info = deviceInfos.Get(device.Host)
 	err = client.Identify()
 	if err != nil {
 		log.Errorln(device.Host + ": " + err.Error())
 		return
 	}
+	deviceInfos.Set(device.Host, client.Info)
+	c.collectDeviceInfo(client.Info, ch, l)
 
 	for _, col := range c.collectors.collectorsForDevice(device) {
-		ct := time.Now()
-		err := col.Collect(client, ch, l)
+		feature := c.collectors.feature(col)
+		if !c.filter.includes(feature) {
+			continue
+		}
+
+		if !c.tagMetrics {
+			c.runCollector(device, feature, col, client, ch, l)
+			continue
+		}
+
+		tagged, done := tagMetrics(ch, feature)
+		c.runCollector(device, feature, col, client, tagged, l)
+		done()
+	}
+
+	c.collectCommandCacheStats(device.Host, ch, l)
+}
+
+// runCollector runs col for device or serves the metrics it returned last if its minimum interval has not passed yet
+func (c *ciscoCollector) runCollector(device *connector.Device, feature string, col collector.RPCCollector, client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) {
+	l := append(labelValues, col.Name())
+
+	minInterval := time.Duration(cfg.MinIntervalForDevice(device.Host, device.Module, feature)) * time.Second
+	if res := results.get(device.Host, device.Module, feature, minInterval); res != nil {
+		c.serveResult(res, ch, l)
+		return
+	}
 
-		if err != nil && err.Error() != "EOF" {
-			log.Errorln(col.Name() + ": " + err.Error())
+	if c.ctx.Err() != nil {
+		ch <- prometheus.MustNewConstMetric(collectorSkippedDesc, prometheus.GaugeValue, 1, l...)
+		return
+	}
+
+	ct := time.Now()
+	var err error
+	if minInterval > 0 {
+		var metrics []prometheus.Metric
+		metrics, err = collectRecorded(col, client, ch, labelValues)
+		// the metrics of an interrupted collector are incomplete and not served again
+		if err == nil && c.ctx.Err() == nil {
+			results.set(device.Host, device.Module, feature, &collectorResult{metrics: metrics, duration: time.Since(ct), collectedAt: ct})
 		}
+	} else {
+		err = col.Collect(client, ch, labelValues)
+	}
 
-		ch <- prometheus.MustNewConstMetric(scrapeCollectorDurationDesc, prometheus.GaugeValue, time.Since(ct).Seconds(), append(l, col.Name())...)
+	if err != nil && err.Error() != "EOF" {
+		log.Errorln(col.Name() + ": " + err.Error())
 	}
+
+	// a collector cut off by the scrape timeout returned incomplete metrics
+	skipped := 0
+	if c.ctx.Err() != nil {
+		skipped = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(scrapeCollectorDurationDesc, prometheus.GaugeValue, time.Since(ct).Seconds(), l...)
+	ch <- prometheus.MustNewConstMetric(collectorSkippedDesc, prometheus.GaugeValue, float64(skipped), l...)
+	ch <- prometheus.MustNewConstMetric(collectorAgeDesc, prometheus.GaugeValue, 0, l...)
 }
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/lwlcom/cisco_exporter/connector"
+	"github.com/lwlcom/cisco_exporter/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// testCollector sends one metric and optionally cancels the scrape while running, like a command cut off by the scrape timeout
+type testCollector struct {
+	cancel context.CancelFunc
+}
+
+var testCollectorDesc = prometheus.NewDesc("test_value", "Value of the test collector", []string{"target"}, nil)
+
+func (c *testCollector) Name() string {
+	return "Test"
+}
+
+func (c *testCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- testCollectorDesc
+}
+
+func (c *testCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	ch <- prometheus.MustNewConstMetric(testCollectorDesc, prometheus.GaugeValue, 1, labelValues...)
+
+	if c.cancel != nil {
+		c.cancel()
+		return errors.New("context canceled")
+	}
+
+	return nil
+}
+
+// runTestCollector runs col like a scrape and returns the value of cisco_collector_skipped
+func runTestCollector(t *testing.T, ctx context.Context, col *testCollector, device *connector.Device) float64 {
+	t.Helper()
+
+	c := &ciscoCollector{ctx: ctx}
+	ch := make(chan prometheus.Metric, 10)
+	c.runCollector(device, "test", col, nil, ch, []string{device.Host})
+	close(ch)
+
+	skipped := -1.0
+	for m := range ch {
+		if m.Desc() != collectorSkippedDesc {
+			continue
+		}
+
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatal(err)
+		}
+		skipped = pb.GetGauge().GetValue()
+	}
+	if skipped < 0 {
+		t.Fatal("cisco_collector_skipped was not sent")
+	}
+
+	return skipped
+}
+
+func TestRunCollectorSkipped(t *testing.T) {
+	defer func(c *config.Config, r *collectorResults) { cfg, results = c, r }(cfg, results)
+	cfg = config.New()
+	cfg.MinIntervals = map[string]int{"test": 60}
+	results = newCollectorResults()
+
+	device := &connector.Device{Host: "router1"}
+
+	t.Run("completed", func(t *testing.T) {
+		if skipped := runTestCollector(t, context.Background(), &testCollector{}, device); skipped != 0 {
+			t.Errorf("got skipped %v, want 0", skipped)
+		}
+		results.invalidate(device.Host)
+	})
+
+	t.Run("timeout reached before", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if skipped := runTestCollector(t, ctx, &testCollector{}, device); skipped != 1 {
+			t.Errorf("got skipped %v, want 1", skipped)
+		}
+	})
+
+	t.Run("interrupted by the timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if skipped := runTestCollector(t, ctx, &testCollector{cancel: cancel}, device); skipped != 1 {
+			t.Errorf("got skipped %v, want 1", skipped)
+		}
+		if res := results.get(device.Host, "", "test", time.Minute); res != nil {
+			t.Error("the incomplete metrics of an interrupted collector must not be served again")
+		}
+	})
+}
+
+func TestCollectDeviceInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		info *rpc.DeviceInfo
+		want int
+	}{
+		{
+			name: "identified by show version",
+			info: &rpc.DeviceInfo{OSType: rpc.IOSXE, Hostname: "switch1", Version: "17.3.4a", Uptime: time.Hour, IdentifiedAt: time.Now()},
+			want: 2,
+		},
+		{
+			name: "identified by transport",
+			info: &rpc.DeviceInfo{OSType: rpc.IOSXE, IdentifiedAt: time.Now()},
+			want: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &ciscoCollector{}
+			ch := make(chan prometheus.Metric, 10)
+			c.collectDeviceInfo(test.info, ch, []string{"switch1"})
+			close(ch)
+
+			if len(ch) != test.want {
+				t.Errorf("got %d metrics, want %d", len(ch), test.want)
+			}
+		})
+	}
+}
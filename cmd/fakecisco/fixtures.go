@@ -0,0 +1,205 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed fixtures
+var builtinFixtures embed.FS
+
+var (
+	unsafeFileNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+	xmlnsRegexp          = regexp.MustCompile(`xmlns(?::[\w-]+)?="([^"]+)"`)
+)
+
+// capabilities announced by NETCONF servers to identify the OS
+var osCapabilities = map[string]string{
+	"iosxe": "http://cisco.com/ns/yang/Cisco-IOS-XE-native?module=Cisco-IOS-XE-native",
+	"nxos":  "http://cisco.com/ns/yang/cisco-nx-os-device?module=Cisco-NX-OS-device",
+}
+
+// fixtures are the answers of the device. Command outputs are read from files named like the command
+// with every character besides letters, digits, dots and dashes replaced by underscores (e.g. show_version.txt).
+// NETCONF data is read from XML files in the subdirectory netconf, named like their root element.
+// Structured output for NX-API is read from JSON files in the subdirectory nxapi, named like the command.
+// RESTCONF data is read from JSON files in the subdirectory restconf, named like the path of the resource.
+// Telemetry streamed to a receiver is read from JSON files in the subdirectory telemetry, one per encoding path.
+// gNMI data is read from JSON files in the subdirectory gnmi, which contain top level containers of openconfig models (JSON_IETF encoded).
+// Command outputs of the security contexts of an ASA are read from the subdirectory contexts/<name>.
+type fixtures struct {
+	commands     map[string]string
+	contexts     map[string]map[string]string
+	netconf      map[string]string
+	nxapi        map[string]string
+	restconf     map[string]string
+	telemetry    map[string]string
+	gnmi         map[string]string
+	capabilities []string
+}
+
+// loadFixtures reads the answers for ostype from the built-in fixtures or from dir if set
+func loadFixtures(ostype, dir string) (*fixtures, error) {
+	var fsys fs.FS
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	} else {
+		var err error
+		fsys, err = fs.Sub(builtinFixtures, path.Join("fixtures", ostype))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f := &fixtures{}
+
+	var err error
+	f.commands, err = readFixtures(fsys, "*.txt")
+	if err != nil {
+		return nil, err
+	}
+	if len(f.commands) == 0 {
+		return nil, errors.Errorf("no fixtures found for %q", ostype)
+	}
+
+	f.contexts, err = readContextFixtures(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	f.netconf, err = readFixtures(fsys, "netconf/*.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	f.capabilities = netconfCapabilities(ostype, f.netconf)
+
+	f.nxapi, err = readFixtures(fsys, "nxapi/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	f.restconf, err = readFixtures(fsys, "restconf/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	f.telemetry, err = readFixtures(fsys, "telemetry/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	f.gnmi, err = readFixtures(fsys, "gnmi/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func readFixtures(fsys fs.FS, pattern string) (map[string]string, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string)
+	for _, name := range files {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read fixture")
+		}
+
+		base := path.Base(name)
+		m[strings.TrimSuffix(base, path.Ext(base))] = string(b)
+	}
+
+	return m, nil
+}
+
+// readContextFixtures reads the command outputs of each security context
+func readContextFixtures(fsys fs.FS) (map[string]map[string]string, error) {
+	dirs, err := fs.Glob(fsys, "contexts/*")
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := make(map[string]map[string]string)
+	for _, dir := range dirs {
+		commands, err := readFixtures(fsys, path.Join(dir, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+		contexts[path.Base(dir)] = commands
+	}
+
+	return contexts, nil
+}
+
+// netconfCapabilities returns the capabilities of the OS and the namespaces used in the NETCONF fixtures
+func netconfCapabilities(ostype string, netconf map[string]string) []string {
+	capabilities := []string{
+		"urn:ietf:params:netconf:base:1.0",
+		"urn:ietf:params:netconf:base:1.1",
+	}
+	if c, found := osCapabilities[ostype]; found {
+		capabilities = append(capabilities, c)
+	}
+
+	seen := make(map[string]bool)
+	for _, data := range netconf {
+		for _, m := range xmlnsRegexp.FindAllStringSubmatch(data, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				capabilities = append(capabilities, m[1])
+			}
+		}
+	}
+
+	return capabilities
+}
+
+// output returns the output of cmd
+func (f *fixtures) output(cmd string) (string, bool) {
+	out, found := f.commands[unsafeFileNameRegexp.ReplaceAllString(strings.TrimSpace(cmd), "_")]
+	return out, found
+}
+
+// contextOutput returns the output of cmd in a security context
+func (f *fixtures) contextOutput(context, cmd string) (string, bool) {
+	out, found := f.contexts[context][unsafeFileNameRegexp.ReplaceAllString(strings.TrimSpace(cmd), "_")]
+	return out, found
+}
+
+// structuredOutput returns the JSON output of cmd
+func (f *fixtures) structuredOutput(cmd string) (string, bool) {
+	out, found := f.nxapi[unsafeFileNameRegexp.ReplaceAllString(strings.TrimSpace(cmd), "_")]
+	return out, found
+}
+
+// restconfData returns the data of the resource at p (e.g. Cisco-IOS-XE-interfaces-oper:interfaces)
+func (f *fixtures) restconfData(p string) (string, bool) {
+	out, found := f.restconf[unsafeFileNameRegexp.ReplaceAllString(strings.Trim(p, "/"), "_")]
+	return out, found
+}
+
+// restconfModules returns the YANG modules of the RESTCONF fixtures
+func (f *fixtures) restconfModules() []string {
+	seen := make(map[string]bool)
+	modules := []string{}
+	for name := range f.restconf {
+		module := strings.SplitN(name, "_", 2)[0]
+		if !seen[module] {
+			seen[module] = true
+			modules = append(modules, module)
+		}
+	}
+
+	return modules
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gnmiListKeys are the keys of the openconfig lists used in the gNMI fixtures
+var gnmiListKeys = map[string][]string{
+	"interface":        {"name"},
+	"subinterface":     {"index"},
+	"component":        {"name"},
+	"channel":          {"index"},
+	"threshold":        {"severity"},
+	"network-instance": {"name"},
+	"protocol":         {"identifier", "name"},
+	"neighbor":         {"neighbor-address"},
+	"afi-safi":         {"afi-safi-name"},
+}
+
+// gnmiLeaf is a value of the fixtures and its path. The first element is qualified with the module name.
+type gnmiLeaf struct {
+	elems []*pb.PathElem
+	value interface{}
+}
+
+// gnmiServer serves the gNMI fixtures. Subscriptions are answered with updates of the single leaves,
+// get requests with the top level containers as one JSON encoded update each (like IOS XE does).
+type gnmiServer struct {
+	pb.UnimplementedGNMIServer
+
+	models   []string
+	trees    map[string]interface{}
+	leaves   []gnmiLeaf
+	interval time.Duration
+}
+
+// newGNMIServer creates a server for the fixtures. The models supported are the modules the members of the fixtures are qualified with.
+func newGNMIServer(f *fixtures, ostype string) (*gnmiServer, error) {
+	s := &gnmiServer{
+		trees:    make(map[string]interface{}),
+		interval: 10 * time.Second,
+	}
+
+	models := make(map[string]bool)
+	if c, found := osCapabilities[ostype]; found {
+		models[strings.SplitN(c, "module=", 2)[1]] = true
+	}
+
+	for name, data := range f.gnmi {
+		var tree map[string]interface{}
+		err := json.Unmarshal([]byte(data), &tree)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid gNMI fixture %s", name)
+		}
+
+		for top, v := range tree {
+			s.trees[top] = v
+
+			leaves, err := flattenGNMI(v, []*pb.PathElem{{Name: top}})
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid gNMI fixture %s", name)
+			}
+			s.leaves = append(s.leaves, leaves...)
+		}
+
+		addGNMIModels(models, tree)
+	}
+
+	for m := range models {
+		s.models = append(s.models, m)
+	}
+	sort.Strings(s.models)
+
+	return s, nil
+}
+
+// addGNMIModels adds the modules members of v are qualified with
+func addGNMIModels(models map[string]bool, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if i := strings.Index(k, ":"); i > 0 {
+				models[k[:i]] = true
+			}
+			addGNMIModels(models, child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			addGNMIModels(models, child)
+		}
+	}
+}
+
+func flattenGNMI(v interface{}, elems []*pb.PathElem) ([]gnmiLeaf, error) {
+	m, isContainer := v.(map[string]interface{})
+	if !isContainer {
+		return []gnmiLeaf{{elems: elems, value: v}}, nil
+	}
+
+	leaves := []gnmiLeaf{}
+	for k, child := range m {
+		name := stripModule(k)
+
+		entries, isList := child.([]interface{})
+		if !isList || len(entries) == 0 {
+			l, err := flattenGNMI(child, appendElem(elems, &pb.PathElem{Name: name}))
+			if err != nil {
+				return nil, err
+			}
+			leaves = append(leaves, l...)
+			continue
+		}
+
+		if _, isLeafList := entries[0].(map[string]interface{}); !isLeafList {
+			leaves = append(leaves, gnmiLeaf{elems: appendElem(elems, &pb.PathElem{Name: name}), value: child})
+			continue
+		}
+
+		keys, found := gnmiListKeys[name]
+		if !found {
+			return nil, errors.Errorf("keys of list %s unknown", name)
+		}
+
+		for _, e := range entries {
+			entry, _ := e.(map[string]interface{})
+			elem := &pb.PathElem{Name: name, Key: make(map[string]string)}
+			for _, key := range keys {
+				elem.Key[key] = fmt.Sprint(entry[key])
+			}
+
+			l, err := flattenGNMI(entry, appendElem(elems, elem))
+			if err != nil {
+				return nil, err
+			}
+			leaves = append(leaves, l...)
+		}
+	}
+
+	return leaves, nil
+}
+
+func appendElem(elems []*pb.PathElem, e *pb.PathElem) []*pb.PathElem {
+	return append(append([]*pb.PathElem{}, elems...), e)
+}
+
+func stripModule(name string) string {
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+// pathMatches checks if elems is below path. Elements of path without keys match all list entries.
+func pathMatches(path, elems []*pb.PathElem) bool {
+	if len(elems) < len(path) {
+		return false
+	}
+
+	for i, p := range path {
+		if stripModule(p.Name) != stripModule(elems[i].Name) {
+			return false
+		}
+
+		for k, v := range p.Key {
+			if v != "*" && elems[i].Key[k] != v {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func checkGNMIAuth(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	user, pass := md.Get("username"), md.Get("password")
+	if len(user) == 0 || len(pass) == 0 || user[0] != *username || pass[0] != *password {
+		return status.Error(codes.Unauthenticated, "authentication failed")
+	}
+
+	return nil
+}
+
+// Capabilities returns the models of the fixtures
+func (s *gnmiServer) Capabilities(ctx context.Context, req *pb.CapabilityRequest) (*pb.CapabilityResponse, error) {
+	err := checkGNMIAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.CapabilityResponse{
+		SupportedEncodings: []pb.Encoding{pb.Encoding_JSON, pb.Encoding_JSON_IETF, pb.Encoding_PROTO},
+		GNMIVersion:        "0.10.0",
+	}
+	for _, m := range s.models {
+		resp.SupportedModels = append(resp.SupportedModels, &pb.ModelData{Name: m, Organization: "fakecisco"})
+	}
+
+	return resp, nil
+}
+
+// Get returns the top level containers matching the paths requested. Using PROTO encoding the matching leaves are returned.
+func (s *gnmiServer) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	err := checkGNMIAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &pb.Notification{Timestamp: time.Now().UnixNano()}
+	for _, p := range req.Path {
+		elems := appendElems(req.GetPrefix().GetElem(), p.GetElem())
+
+		if req.Encoding == pb.Encoding_PROTO {
+			updates, err := s.updates(elems, req.Encoding)
+			if err != nil {
+				return nil, err
+			}
+			n.Update = append(n.Update, updates...)
+			continue
+		}
+
+		for top, tree := range s.trees {
+			if len(elems) > 0 && stripModule(elems[0].Name) != stripModule(top) {
+				continue
+			}
+
+			val, err := typedValue(tree, req.Encoding)
+			if err != nil {
+				return nil, err
+			}
+			n.Update = append(n.Update, &pb.Update{Path: &pb.Path{Elem: []*pb.PathElem{{Name: top}}}, Val: val})
+		}
+	}
+
+	return &pb.GetResponse{Notification: []*pb.Notification{n}}, nil
+}
+
+// Subscribe sends the leaves matching the subscriptions every sample interval (STREAM) or once (ONCE)
+func (s *gnmiServer) Subscribe(stream pb.GNMI_SubscribeServer) error {
+	err := checkGNMIAuth(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	list := req.GetSubscribe()
+	if list == nil {
+		return status.Error(codes.InvalidArgument, "first request has to be a subscription list")
+	}
+	if list.Mode == pb.SubscriptionList_POLL {
+		return status.Error(codes.Unimplemented, "poll subscriptions are not supported")
+	}
+
+	interval := s.interval
+	for _, sub := range list.Subscription {
+		if sub.SampleInterval > 0 {
+			interval = time.Duration(sub.SampleInterval)
+		}
+	}
+	log.Infof("gNMI subscription to %d paths (%s)", len(list.Subscription), list.Mode)
+
+	synced := false
+	for {
+		for _, sub := range list.Subscription {
+			updates, err := s.updates(appendElems(list.GetPrefix().GetElem(), sub.GetPath().GetElem()), list.Encoding)
+			if err != nil {
+				return err
+			}
+
+			n := &pb.Notification{Timestamp: time.Now().UnixNano(), Update: updates}
+			err = stream.Send(&pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: n}})
+			if err != nil {
+				return err
+			}
+		}
+
+		if !synced {
+			err = stream.Send(&pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{SyncResponse: true}})
+			if err != nil {
+				return err
+			}
+			synced = true
+		}
+
+		if list.Mode == pb.SubscriptionList_ONCE {
+			return nil
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func appendElems(prefix, path []*pb.PathElem) []*pb.PathElem {
+	return append(append([]*pb.PathElem{}, prefix...), path...)
+}
+
+func (s *gnmiServer) updates(path []*pb.PathElem, encoding pb.Encoding) ([]*pb.Update, error) {
+	updates := []*pb.Update{}
+	for _, l := range s.leaves {
+		if !pathMatches(path, l.elems) {
+			continue
+		}
+
+		val, err := typedValue(l.value, encoding)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, &pb.Update{Path: &pb.Path{Elem: l.elems}, Val: val})
+	}
+
+	return updates, nil
+}
+
+func typedValue(v interface{}, encoding pb.Encoding) (*pb.TypedValue, error) {
+	switch encoding {
+	case pb.Encoding_JSON, pb.Encoding_JSON_IETF:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if encoding == pb.Encoding_JSON {
+			return &pb.TypedValue{Value: &pb.TypedValue_JsonVal{JsonVal: b}}, nil
+		}
+		return &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: b}}, nil
+	case pb.Encoding_PROTO:
+		switch v := v.(type) {
+		case string:
+			return &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: v}}, nil
+		case bool:
+			return &pb.TypedValue{Value: &pb.TypedValue_BoolVal{BoolVal: v}}, nil
+		case float64:
+			if v == math.Trunc(v) && v >= 0 {
+				return &pb.TypedValue{Value: &pb.TypedValue_UintVal{UintVal: uint64(v)}}, nil
+			}
+			if v == math.Trunc(v) {
+				return &pb.TypedValue{Value: &pb.TypedValue_IntVal{IntVal: int64(v)}}, nil
+			}
+			return &pb.TypedValue{Value: &pb.TypedValue_DoubleVal{DoubleVal: v}}, nil
+		}
+
+		return nil, status.Errorf(codes.Unimplemented, "values of type %T are not supported using PROTO encoding", v)
+	}
+
+	return nil, status.Errorf(codes.Unimplemented, "unsupported encoding %s", encoding)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/lwlcom/cisco_exporter/connector"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// gnmiStandIn serves the gNMI fixtures of fakecisco in memory. The server can be restarted to imitate a device
+// which dropped its sessions.
+type gnmiStandIn struct {
+	gs *gnmiServer
+
+	mu  sync.Mutex
+	lis *bufconn.Listener
+	srv *grpc.Server
+}
+
+func newGNMIStandIn(t *testing.T, ostype string) *gnmiStandIn {
+	t.Helper()
+
+	f, err := loadFixtures(ostype, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs, err := newGNMIServer(f, ostype)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &gnmiStandIn{gs: gs}
+	s.start()
+	t.Cleanup(s.stop)
+
+	return s
+}
+
+func (s *gnmiStandIn) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lis = bufconn.Listen(1 << 20)
+	s.srv = grpc.NewServer()
+	pb.RegisterGNMIServer(s.srv, s.gs)
+	go s.srv.Serve(s.lis)
+}
+
+func (s *gnmiStandIn) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.srv.Stop()
+}
+
+func (s *gnmiStandIn) dial(ctx context.Context, addr string) (net.Conn, error) {
+	s.mu.Lock()
+	lis := s.lis
+	s.mu.Unlock()
+
+	return lis.DialContext(ctx)
+}
+
+func gnmiTestDevice(mode, encoding string) *connector.Device {
+	return &connector.Device{
+		Host:      "router1",
+		Transport: connector.TransportGNMI,
+		Username:  *username,
+		Password:  *password,
+		GNMI: &config.GNMIConfig{
+			Port:           57400,
+			Plaintext:      true,
+			Mode:           mode,
+			Encoding:       encoding,
+			SampleInterval: 1,
+		},
+	}
+}
+
+// interfaceOperStatus returns the oper-status of each interface in the tree of /interfaces
+func interfaceOperStatus(t *testing.T, tree map[string]interface{}) map[string]interface{} {
+	t.Helper()
+
+	interfaces, _ := tree["interfaces"].(map[string]interface{})
+	list, _ := interfaces["interface"].([]interface{})
+	if len(list) == 0 {
+		t.Fatalf("no interfaces in %v", tree)
+	}
+
+	status := make(map[string]interface{})
+	for _, entry := range list {
+		e := entry.(map[string]interface{})
+		state, _ := e["state"].(map[string]interface{})
+		status[e["name"].(string)] = state["oper-status"]
+	}
+
+	return status
+}
+
+func TestGNMISession(t *testing.T) {
+	tests := []struct {
+		mode     string
+		encoding string
+	}{
+		{mode: connector.GNMIModeSubscribe, encoding: "json_ietf"},
+		{mode: connector.GNMIModeSubscribe, encoding: "proto"},
+		{mode: connector.GNMIModeGet, encoding: "json_ietf"},
+		{mode: connector.GNMIModeGet, encoding: "proto"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode+"/"+test.encoding, func(t *testing.T) {
+			standIn := newGNMIStandIn(t, "iosxe")
+			m := connector.NewGNMIManager(grpc.WithContextDialer(standIn.dial))
+			defer m.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			s, err := m.Session(ctx, gnmiTestDevice(test.mode, test.encoding), config.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.HasModel("openconfig-interfaces") {
+				t.Errorf("got models %v, want openconfig-interfaces among them", s.Models())
+			}
+
+			tree, err := s.Get(ctx, "/interfaces")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{"TenGigabitEthernet1/0/1": "UP", "TenGigabitEthernet1/0/2": "DOWN"}
+			got := interfaceOperStatus(t, tree)
+			for name, status := range want {
+				if got[name] != status {
+					t.Errorf("%s: got oper-status %v, want %v", name, got[name], status)
+				}
+			}
+			if _, found := tree["components"]; found {
+				t.Error("got components, want only the interfaces queried")
+			}
+		})
+	}
+}
+
+func TestGNMIManagerReplacesFailedSession(t *testing.T) {
+	standIn := newGNMIStandIn(t, "iosxe")
+	m := connector.NewGNMIManager(grpc.WithContextDialer(standIn.dial))
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	device := gnmiTestDevice(connector.GNMIModeGet, "json_ietf")
+	s, err := m.Session(ctx, device, config.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same, err := m.Session(ctx, device, config.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same != s {
+		t.Error("a working session has to be reused")
+	}
+
+	standIn.stop()
+	if _, err := s.Get(ctx, "/interfaces"); err == nil {
+		t.Fatal("expected the get to fail while the server is down")
+	}
+	standIn.start()
+
+	replaced, err := m.Session(ctx, device, config.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced == s {
+		t.Fatal("the failed session has to be replaced")
+	}
+	if _, err := replaced.Get(ctx, "/interfaces"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,178 @@
+// fakecisco is an SSH server imitating the CLI of a Cisco device.
+// It answers the commands sent by cisco_exporter with fixture files, so the exporter can be tested without hardware.
+// Optionally NX-API is served using plain HTTP, RESTCONF using HTTPS and gNMI using TLS, and telemetry is streamed to a receiver.
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	listenAddress     = flag.String("listen-address", "127.0.0.1:2222", "Address to listen on for SSH connections")
+	nxapiAddress      = flag.String("nxapi-listen-address", "", "Address to serve NX-API on using HTTP (empty disables NX-API)")
+	restconfAddress   = flag.String("restconf-listen-address", "", "Address to serve RESTCONF on using HTTPS (empty disables RESTCONF)")
+	gnmiAddress       = flag.String("gnmi-listen-address", "", "Address to serve gNMI on using TLS (empty disables gNMI)")
+	tlsCert           = flag.String("tls-cert", "fakecisco.crt", "Certificate file for RESTCONF and gNMI (a self-signed certificate is written to it if missing)")
+	tlsKey            = flag.String("tls-key", "fakecisco.key", "Key file for RESTCONF and gNMI (written along with a generated certificate)")
+	telemetryAddress  = flag.String("telemetry-dial-address", "", "Address of the telemetry receiver to stream to using gRPC dial-out (empty disables telemetry)")
+	telemetryInterval = flag.Duration("telemetry-interval", 10*time.Second, "Interval telemetry is sent in")
+	osType            = flag.String("os", "ios", "OS to imitate (ios, iosxe, iosxr, nxos, asa)")
+	fixturesDir       = flag.String("fixtures", "", "Directory with command outputs to use instead of the built-in fixtures")
+	hostname          = flag.String("hostname", "switch1", "Hostname shown in the prompt")
+	username          = flag.String("username", "admin", "Username accepted for login")
+	password          = flag.String("password", "admin", "Password accepted for login (empty disables password authentication)")
+	authorizedKeys    = flag.String("authorized-keys", "", "Authorized keys file for public key authentication")
+	hostKeyFile       = flag.String("host-key", "", "Private host key file (a new key is generated on start if empty)")
+	enablePassword    = flag.String("enable-password", "", "Start in user exec mode and require this secret for enable")
+	delay             = flag.Duration("delay", 0, "Delay before the output of a command is sent")
+	chunkSize         = flag.Int("chunk-size", 0, "Send output in chunks of this many bytes (0 = all at once)")
+	chunkDelay        = flag.Duration("chunk-delay", 0, "Delay between chunks of output")
+	hangCommands      = flag.String("hang", "", "Comma separated list of commands never answered")
+)
+
+// osPromptPrefixes are shown before the hostname in the prompt (IOS XR shows the active route processor)
+var osPromptPrefixes = map[string]string{
+	"iosxr": "RP/0/RSP0/CPU0:",
+}
+
+// osPagerCommands disable paging of the output (ASA uses terminal pager instead of terminal length)
+var osPagerCommands = map[string]string{
+	"asa": "terminal pager",
+}
+
+func main() {
+	flag.Parse()
+
+	f, err := loadFixtures(*osType, *fixturesDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg, err := serverConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", *listenAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Imitating %s device %s on %s", *osType, *hostname, l.Addr())
+
+	s := &server{
+		config:   cfg,
+		fixtures: f,
+		behavior: behavior{
+			hostname:       *hostname,
+			promptPrefix:   osPromptPrefixes[*osType],
+			timestamps:     *osType == "iosxr",
+			pagerCommand:   pagerCommand(*osType),
+			enablePassword: *enablePassword,
+			delay:          *delay,
+			chunkSize:      *chunkSize,
+			chunkDelay:     *chunkDelay,
+			hang:           hangingCommands(*hangCommands),
+		},
+	}
+
+	if *nxapiAddress != "" {
+		go serveNXAPI(&nxapiHandler{fixtures: f, behavior: s.behavior})
+	}
+
+	if *restconfAddress != "" {
+		tlsConfig, err := serverTLSConfig(*tlsCert, *tlsKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go serveRestconf(&restconfHandler{fixtures: f, behavior: s.behavior}, tlsConfig)
+	}
+
+	if *gnmiAddress != "" {
+		tlsConfig, err := serverTLSConfig(*tlsCert, *tlsKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		gs, err := newGNMIServer(f, *osType)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go serveGNMI(gs, tlsConfig)
+	}
+
+	if *telemetryAddress != "" {
+		go (&telemetrySender{address: *telemetryAddress, interval: *telemetryInterval, fixtures: f}).run()
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			log.Errorln(err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		go s.handleConnection(conn)
+	}
+}
+
+func serveNXAPI(h http.Handler) {
+	log.Infof("Serving NX-API on %s", *nxapiAddress)
+	log.Fatal(http.ListenAndServe(*nxapiAddress, h))
+}
+
+func serveRestconf(h http.Handler, tlsConfig *tls.Config) {
+	srv := &http.Server{
+		Addr:      *restconfAddress,
+		Handler:   h,
+		TLSConfig: tlsConfig,
+	}
+
+	log.Infof("Serving RESTCONF on %s", *restconfAddress)
+	log.Fatal(srv.ListenAndServeTLS("", ""))
+}
+
+func serveGNMI(gs *gnmiServer, tlsConfig *tls.Config) {
+	l, err := net.Listen("tcp", *gnmiAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	pb.RegisterGNMIServer(srv, gs)
+
+	log.Infof("Serving gNMI on %s", l.Addr())
+	log.Fatal(srv.Serve(l))
+}
+
+func hangingCommands(list string) map[string]bool {
+	hang := make(map[string]bool)
+	for _, cmd := range strings.Split(list, ",") {
+		cmd = strings.TrimSpace(cmd)
+		if cmd != "" {
+			hang[cmd] = true
+		}
+	}
+
+	return hang
+}
+
+func pagerCommand(ostype string) string {
+	if cmd, found := osPagerCommands[ostype]; found {
+		return cmd
+	}
+
+	return "terminal length"
+}
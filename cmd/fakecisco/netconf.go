@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	netconfBase10   = "urn:ietf:params:netconf:base:1.0"
+	netconfBase11   = "urn:ietf:params:netconf:base:1.1"
+	netconfEndOfMsg = "]]>]]>"
+)
+
+type netconfHello struct {
+	Capabilities []string `xml:"capabilities>capability"`
+}
+
+type netconfRPC struct {
+	MessageID string `xml:"message-id,attr"`
+	Get       *struct {
+		Filter struct {
+			Inner []byte `xml:",innerxml"`
+		} `xml:"filter"`
+	} `xml:"get"`
+	CloseSession *struct{} `xml:"close-session"`
+}
+
+// netconfServer answers get requests with the NETCONF fixtures
+type netconfServer struct {
+	rw       io.ReadWriter
+	r        *bufio.Reader
+	fixtures *fixtures
+	behavior behavior
+	chunked  bool
+}
+
+func (s *netconfServer) run() error {
+	s.r = bufio.NewReader(s.rw)
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `<?xml version="1.0" encoding="UTF-8"?><hello xmlns="%s"><capabilities>`, netconfBase10)
+	for _, c := range s.fixtures.capabilities {
+		fmt.Fprintf(b, "<capability>%s</capability>", c)
+	}
+	b.WriteString("</capabilities><session-id>1</session-id></hello>")
+
+	err := s.write(b.String())
+	if err != nil {
+		return err
+	}
+
+	msg, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	hello := &netconfHello{}
+	err = xml.Unmarshal(msg, hello)
+	if err != nil {
+		return errors.Wrap(err, "could not parse hello")
+	}
+	for _, c := range hello.Capabilities {
+		if strings.TrimSpace(c) == netconfBase11 {
+			s.chunked = true
+		}
+	}
+
+	for {
+		msg, err := s.read()
+		if err != nil {
+			return err
+		}
+
+		rpc := &netconfRPC{}
+		err = xml.Unmarshal(msg, rpc)
+		if err != nil {
+			return errors.Wrap(err, "could not parse rpc")
+		}
+
+		reply := fmt.Sprintf(`<rpc-reply message-id="%s" xmlns="%s">`, rpc.MessageID, netconfBase10)
+		switch {
+		case rpc.Get != nil:
+			data, err := s.data(rpc.Get.Filter.Inner)
+			if err != nil {
+				return err
+			}
+			reply += "<data>" + data + "</data>"
+		case rpc.CloseSession != nil:
+			return s.write(reply + "<ok/></rpc-reply>")
+		default:
+			reply += "<rpc-error><error-type>protocol</error-type><error-tag>operation-not-supported</error-tag>" +
+				"<error-severity>error</error-severity></rpc-error>"
+		}
+
+		time.Sleep(s.behavior.delay)
+
+		err = s.write(reply + "</rpc-reply>")
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// data returns the fixtures for the top level elements of the subtree filter
+func (s *netconfServer) data(filter []byte) (string, error) {
+	var data string
+
+	d := xml.NewDecoder(bytes.NewReader(filter))
+	depth := 0
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "could not parse filter")
+		}
+
+		switch e := t.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				data += s.fixtures.netconf[e.Name.Local]
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+func (s *netconfServer) write(msg string) error {
+	if s.chunked {
+		_, err := fmt.Fprintf(s.rw, "\n#%d\n%s\n##\n", len(msg), msg)
+		return err
+	}
+
+	_, err := io.WriteString(s.rw, msg+netconfEndOfMsg)
+	return err
+}
+
+func (s *netconfServer) read() ([]byte, error) {
+	if !s.chunked {
+		var msg []byte
+		for {
+			b, err := s.r.ReadBytes('>')
+			if err != nil {
+				return nil, err
+			}
+			msg = append(msg, b...)
+
+			if bytes.HasSuffix(msg, []byte(netconfEndOfMsg)) {
+				return msg[:len(msg)-len(netconfEndOfMsg)], nil
+			}
+		}
+	}
+
+	var msg []byte
+	for {
+		header, err := s.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		header = strings.TrimSpace(header)
+		switch {
+		case header == "":
+			continue
+		case header == "##":
+			return msg, nil
+		case !strings.HasPrefix(header, "#"):
+			return nil, errors.Errorf("invalid chunk header %q", header)
+		}
+
+		size, err := strconv.Atoi(header[1:])
+		if err != nil {
+			return nil, errors.Errorf("invalid chunk header %q", header)
+		}
+
+		chunk := make([]byte, size)
+		_, err = io.ReadFull(s.r, chunk)
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, chunk...)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type nxapiRequest struct {
+	InsAPI struct {
+		Type  string `json:"type"`
+		Input string `json:"input"`
+	} `json:"ins_api"`
+}
+
+type nxapiOutput struct {
+	Input    string          `json:"input"`
+	Msg      string          `json:"msg"`
+	Code     string          `json:"code"`
+	CLIError string          `json:"clierror,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+}
+
+// nxapiHandler answers NX-API requests of type cli_show with the JSON fixtures and cli_show_ascii with the command outputs
+type nxapiHandler struct {
+	fixtures *fixtures
+	behavior behavior
+}
+
+func (h *nxapiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/ins" {
+		http.NotFound(w, r)
+		return
+	}
+
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != *username || pass != *password {
+		w.Header().Set("WWW-Authenticate", `Basic realm="nxapi"`)
+		http.Error(w, "authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	req := &nxapiRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	cmd := req.InsAPI.Input
+	if h.behavior.hang[cmd] {
+		<-r.Context().Done()
+		return
+	}
+
+	out := nxapiOutput{Input: cmd, Msg: "Success", Code: "200"}
+	found := false
+	switch req.InsAPI.Type {
+	case "cli_show":
+		var body string
+		body, found = h.fixtures.structuredOutput(cmd)
+		out.Body = json.RawMessage(body)
+	case "cli_show_ascii":
+		var body string
+		body, found = h.fixtures.output(cmd)
+		out.Body, _ = json.Marshal(body)
+	}
+
+	status := http.StatusOK
+	if !found {
+		out = nxapiOutput{Input: cmd, Msg: "Input CLI command error", Code: "400", CLIError: "% Invalid command\n"}
+		status = http.StatusInternalServerError
+	}
+
+	time.Sleep(h.behavior.delay)
+
+	resp := map[string]interface{}{
+		"ins_api": map[string]interface{}{
+			"type":    req.InsAPI.Type,
+			"version": "1.0",
+			"sid":     "eoc",
+			"outputs": map[string]interface{}{"output": out},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const restconfDataPath = "/restconf/data/"
+
+// restconfHandler answers RESTCONF get requests with the JSON fixtures.
+// The YANG library lists the modules used in the names of the fixtures.
+type restconfHandler struct {
+	fixtures *fixtures
+	behavior behavior
+}
+
+func (h *restconfHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, restconfDataPath) {
+		http.NotFound(w, r)
+		return
+	}
+
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != *username || pass != *password {
+		w.Header().Set("WWW-Authenticate", `Basic realm="restconf"`)
+		http.Error(w, "authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, restconfDataPath)
+	if h.behavior.hang[p] {
+		<-r.Context().Done()
+		return
+	}
+
+	var body []byte
+	if p == "ietf-yang-library:modules-state" {
+		body = h.modulesState()
+	} else if data, found := h.fixtures.restconfData(p); found {
+		body = []byte(data)
+	} else {
+		http.Error(w, "uri keypath not found", http.StatusNotFound)
+		return
+	}
+
+	time.Sleep(h.behavior.delay)
+
+	w.Header().Set("Content-Type", "application/yang-data+json")
+	w.Write(body)
+}
+
+func (h *restconfHandler) modulesState() []byte {
+	type module struct {
+		Name string `json:"name"`
+	}
+
+	modules := []module{}
+	for _, name := range h.fixtures.restconfModules() {
+		modules = append(modules, module{Name: name})
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+
+	b, _ := json.Marshal(map[string]interface{}{
+		"ietf-yang-library:modules-state": map[string]interface{}{"module": modules},
+	})
+
+	return b
+}
+
+// restconfTLSConfig loads the certificate of the server from certFile and keyFile.
+// If the files do not exist a self-signed certificate for localhost is generated and written to them,
+// so it can be used as CA file by the exporter.
+func serverTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("certificate and key file are required for RESTCONF and gNMI")
+	}
+
+	if _, err := os.Stat(certFile); os.IsNotExist(err) {
+		err = generateCertificate(certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not generate certificate")
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load certificate")
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+func generateCertificate(certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: *hostname},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost", *hostname},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+}
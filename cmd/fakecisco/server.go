@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+func serverConfig() (*ssh.ServerConfig, error) {
+	cfg := &ssh.ServerConfig{}
+
+	if *password != "" {
+		cfg.PasswordCallback = func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == *username && string(pass) == *password {
+				return nil, nil
+			}
+
+			return nil, errors.Errorf("password rejected for %s", c.User())
+		}
+	}
+
+	if *authorizedKeys != "" {
+		keys, err := loadAuthorizedKeys(*authorizedKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.PublicKeyCallback = func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == *username && keys[string(key.Marshal())] {
+				return nil, nil
+			}
+
+			return nil, errors.Errorf("public key rejected for %s", c.User())
+		}
+	}
+
+	hostKey, err := loadHostKey(*hostKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AddHostKey(hostKey)
+
+	return cfg, nil
+}
+
+func loadAuthorizedKeys(file string) (map[string]bool, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read authorized keys")
+	}
+
+	keys := make(map[string]bool)
+	for len(bytes.TrimSpace(b)) > 0 {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(b)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse authorized keys")
+		}
+
+		keys[string(key.Marshal())] = true
+		b = rest
+	}
+
+	return keys, nil
+}
+
+func loadHostKey(file string) (ssh.Signer, error) {
+	if file == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return ssh.NewSignerFromKey(key)
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read host key")
+	}
+
+	return ssh.ParsePrivateKey(b)
+}
+
+// behavior describes how the device answers on the CLI
+type behavior struct {
+	hostname       string
+	promptPrefix   string
+	timestamps     bool
+	pagerCommand   string
+	enablePassword string
+	delay          time.Duration
+	chunkSize      int
+	chunkDelay     time.Duration
+	hang           map[string]bool
+}
+
+type server struct {
+	config   *ssh.ServerConfig
+	fixtures *fixtures
+	behavior behavior
+}
+
+func (s *server) handleConnection(conn net.Conn) {
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		log.Warnf("%s: handshake failed: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+	log.Infof("%s: logged in as %s", conn.RemoteAddr(), sshConn.User())
+
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		ch, requests, err := newChannel.Accept()
+		if err != nil {
+			log.Errorln(err)
+			continue
+		}
+
+		go s.handleSession(ch, requests)
+	}
+}
+
+func (s *server) handleSession(ch ssh.Channel, requests <-chan *ssh.Request) {
+	for req := range requests {
+		switch req.Type {
+		case "pty-req":
+			req.Reply(true, nil)
+		case "shell":
+			req.Reply(true, nil)
+
+			sh := &shell{
+				rw:         ch,
+				fixtures:   s.fixtures,
+				behavior:   s.behavior,
+				privileged: s.behavior.enablePassword == "",
+			}
+			go func() {
+				sh.run()
+				ch.Close()
+			}()
+		case "subsystem":
+			var subsystem struct{ Name string }
+			if ssh.Unmarshal(req.Payload, &subsystem) != nil || subsystem.Name != "netconf" || len(s.fixtures.netconf) == 0 {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+
+			nc := &netconfServer{
+				rw:       ch,
+				fixtures: s.fixtures,
+				behavior: s.behavior,
+			}
+			go func() {
+				err := nc.run()
+				if err != nil && err != io.EOF {
+					log.Warnf("netconf: %v", err)
+				}
+				ch.Close()
+			}()
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// shell imitates the CLI of the device in a session
+type shell struct {
+	rw         io.ReadWriter
+	fixtures   *fixtures
+	behavior   behavior
+	privileged bool
+	context    string
+}
+
+func (s *shell) run() {
+	r := bufio.NewReader(s.rw)
+	s.write(s.prompt())
+
+	for {
+		line, err := s.readLine(r)
+		if err != nil {
+			return
+		}
+
+		if !s.handle(r, line) {
+			return
+		}
+	}
+}
+
+// handle answers a line entered on the CLI and returns false if the session ends
+func (s *shell) handle(r *bufio.Reader, cmd string) bool {
+	switch {
+	case cmd == "":
+		s.write("\n" + s.prompt())
+	case cmd == "exit" || cmd == "quit":
+		return false
+	case s.behavior.hang[cmd]:
+		s.write(cmd + "\n")
+	case strings.HasPrefix(cmd, s.behavior.pagerCommand):
+		s.write(cmd + "\n" + s.prompt())
+	case cmd == "enable":
+		s.enable(r)
+	case strings.HasPrefix(cmd, "changeto ") && len(s.fixtures.contexts) > 0:
+		s.changeto(strings.TrimPrefix(cmd, "changeto "))
+	default:
+		out, found := s.output(cmd)
+		if !found {
+			out = "          ^\n% Invalid input detected at '^' marker.\n\n"
+		}
+
+		if s.behavior.timestamps {
+			// IOS XR prints the time before the output of every command
+			out = time.Now().UTC().Format("Mon Jan 2 15:04:05.000 MST") + "\n" + out
+		}
+
+		time.Sleep(s.behavior.delay)
+		s.write(cmd + "\n" + out + s.prompt())
+	}
+
+	return true
+}
+
+func (s *shell) enable(r *bufio.Reader) {
+	if s.privileged {
+		s.write("enable\n" + s.prompt())
+		return
+	}
+
+	s.write("enable\nPassword: ")
+	secret, err := s.readLine(r)
+	if err != nil {
+		return
+	}
+
+	if secret != s.behavior.enablePassword {
+		s.write("\n% Access denied\n\n" + s.prompt())
+		return
+	}
+
+	s.privileged = true
+	s.write("\n" + s.prompt())
+}
+
+// changeto changes to a security context (changeto context <name>) or back to the system context (changeto system)
+func (s *shell) changeto(target string) {
+	cmd := "changeto " + target
+	switch {
+	case target == "system":
+		s.context = ""
+	case strings.HasPrefix(target, "context "):
+		name := strings.TrimSpace(strings.TrimPrefix(target, "context "))
+		if _, found := s.fixtures.contexts[name]; !found {
+			s.write(cmd + "\nERROR: Context " + name + " does not exist\n" + s.prompt())
+			return
+		}
+		s.context = name
+	default:
+		s.write(cmd + "\nERROR: % Invalid input detected at '^' marker.\n" + s.prompt())
+		return
+	}
+
+	s.write(cmd + "\n" + s.prompt())
+}
+
+// output returns the output of cmd in the current context
+func (s *shell) output(cmd string) (string, bool) {
+	if s.context != "" {
+		return s.fixtures.contextOutput(s.context, cmd)
+	}
+
+	return s.fixtures.output(cmd)
+}
+
+func (s *shell) prompt() string {
+	name := s.behavior.promptPrefix + s.behavior.hostname
+	if s.context != "" {
+		name += "/" + s.context
+	}
+
+	if s.privileged {
+		return name + "#"
+	}
+
+	return name + ">"
+}
+
+func (s *shell) readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// write sends output with line endings of a terminal, in chunks if configured
+func (s *shell) write(output string) {
+	b := []byte(strings.ReplaceAll(output, "\n", "\r\n"))
+
+	size := s.behavior.chunkSize
+	if size <= 0 {
+		size = len(b)
+	}
+
+	for len(b) > 0 {
+		n := size
+		if n > len(b) {
+			n = len(b)
+		}
+
+		_, err := s.rw.Write(b[:n])
+		if err != nil {
+			return
+		}
+		b = b[n:]
+
+		if len(b) > 0 {
+			time.Sleep(s.behavior.chunkDelay)
+		}
+	}
+}
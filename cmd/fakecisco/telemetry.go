@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/telemetry/mdt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// telemetryFixture is the data sent for an encoding path. Rows consist of keys and content,
+// which are encoded as self-describing GPB (kvGPB) fields.
+type telemetryFixture struct {
+	EncodingPath string `json:"encoding_path"`
+	Rows         []struct {
+		Keys    map[string]interface{} `json:"keys"`
+		Content map[string]interface{} `json:"content"`
+	} `json:"rows"`
+}
+
+// telemetrySender streams the telemetry fixtures to a receiver (gRPC dial-out) like a device with periodic subscriptions
+type telemetrySender struct {
+	address  string
+	interval time.Duration
+	fixtures *fixtures
+}
+
+// run connects to the receiver and sends the telemetry every interval, reconnecting on errors
+func (s *telemetrySender) run() {
+	var collectionID uint64
+	for {
+		err := s.stream(&collectionID)
+		log.Errorf("telemetry stream to %s: %v", s.address, err)
+		time.Sleep(s.interval)
+	}
+}
+
+func (s *telemetrySender) stream(collectionID *uint64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, s.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := mdt.NewGRPCMdtDialoutClient(conn).MdtDialout(ctx)
+	if err != nil {
+		return err
+	}
+	log.Infof("Streaming telemetry to %s", s.address)
+
+	for {
+		*collectionID++
+		for _, name := range s.fixtureNames() {
+			msg, err := telemetryMessage(s.fixtures.telemetry[name], *collectionID)
+			if err != nil {
+				return errors.Wrapf(err, "invalid telemetry fixture %s", name)
+			}
+
+			err = stream.Send(&mdt.MdtDialoutArgs{ReqId: int64(*collectionID), Data: msg})
+			if err != nil {
+				return err
+			}
+		}
+
+		time.Sleep(s.interval)
+	}
+}
+
+func (s *telemetrySender) fixtureNames() []string {
+	names := make([]string, 0, len(s.fixtures.telemetry))
+	for name := range s.fixtures.telemetry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func telemetryMessage(fixture string, collectionID uint64) ([]byte, error) {
+	f := &telemetryFixture{}
+	err := json.Unmarshal([]byte(fixture), f)
+	if err != nil {
+		return nil, err
+	}
+
+	now := uint64(time.Now().UnixMilli())
+	msg := &mdt.Telemetry{
+		NodeId:              &mdt.Telemetry_NodeIdStr{NodeIdStr: *hostname},
+		Subscription:        &mdt.Telemetry_SubscriptionIdStr{SubscriptionIdStr: "101"},
+		EncodingPath:        f.EncodingPath,
+		CollectionId:        collectionID,
+		CollectionStartTime: now,
+		MsgTimestamp:        now,
+		CollectionEndTime:   now,
+	}
+	for _, r := range f.Rows {
+		msg.DataGpbkv = append(msg.DataGpbkv, &mdt.TelemetryField{
+			Timestamp: now,
+			Fields: []*mdt.TelemetryField{
+				{Name: "keys", Fields: telemetryFields(r.Keys)},
+				{Name: "content", Fields: telemetryFields(r.Content)},
+			},
+		})
+	}
+
+	return proto.Marshal(msg)
+}
+
+// telemetryFields encodes m as fields. Lists are sent as repeated fields with the same name.
+func telemetryFields(m map[string]interface{}) []*mdt.TelemetryField {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := []*mdt.TelemetryField{}
+	for _, k := range keys {
+		if l, isList := m[k].([]interface{}); isList {
+			for _, v := range l {
+				fields = append(fields, telemetryField(k, v))
+			}
+			continue
+		}
+
+		fields = append(fields, telemetryField(k, m[k]))
+	}
+
+	return fields
+}
+
+func telemetryField(name string, v interface{}) *mdt.TelemetryField {
+	f := &mdt.TelemetryField{Name: name}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		f.Fields = telemetryFields(v)
+	case string:
+		f.ValueByType = &mdt.TelemetryField_StringValue{StringValue: v}
+	case bool:
+		f.ValueByType = &mdt.TelemetryField_BoolValue{BoolValue: v}
+	case float64:
+		if v >= 0 && v == math.Trunc(v) {
+			f.ValueByType = &mdt.TelemetryField_Uint64Value{Uint64Value: uint64(v)}
+		} else {
+			f.ValueByType = &mdt.TelemetryField_DoubleValue{DoubleValue: v}
+		}
+	}
+
+	return f
+}
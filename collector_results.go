@@ -0,0 +1,86 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/collector"
+	"github.com/lwlcom/cisco_exporter/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectorResult are the metrics of a collector run for a device
+type collectorResult struct {
+	metrics     []prometheus.Metric
+	duration    time.Duration
+	collectedAt time.Time
+}
+
+// collectorResultKey identifies the result of a collector (by feature name) among the ones of a host. Scrapes with a
+// module run different collectors and commands, so their results are kept apart.
+type collectorResultKey struct {
+	module  string
+	feature string
+}
+
+// collectorResults keeps the results of collectors with a minimum interval, which are served until the interval has passed
+type collectorResults struct {
+	mu      sync.Mutex
+	results map[string]map[collectorResultKey]*collectorResult
+}
+
+func newCollectorResults() *collectorResults {
+	return &collectorResults{results: make(map[string]map[collectorResultKey]*collectorResult)}
+}
+
+// get returns the result of the collector (by feature name) for host scraped with module, nil if it is older than minInterval
+func (r *collectorResults) get(host, module, feature string, minInterval time.Duration) *collectorResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, found := r.results[host][collectorResultKey{module: module, feature: feature}]
+	if !found || time.Since(res.collectedAt) >= minInterval {
+		return nil
+	}
+
+	return res
+}
+
+func (r *collectorResults) set(host, module, feature string, res *collectorResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.results[host] == nil {
+		r.results[host] = make(map[collectorResultKey]*collectorResult)
+	}
+	r.results[host][collectorResultKey{module: module, feature: feature}] = res
+}
+
+// invalidate removes the results of host for all modules, e.g. because it might have been reloaded
+func (r *collectorResults) invalidate(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.results, host)
+}
+
+// collectRecorded runs col and returns its metrics along with sending them to ch
+func collectRecorded(col collector.RPCCollector, client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) ([]prometheus.Metric, error) {
+	metrics := make(chan prometheus.Metric)
+	done := make(chan struct{})
+
+	recorded := []prometheus.Metric{}
+	go func() {
+		for m := range metrics {
+			recorded = append(recorded, m)
+			ch <- m
+		}
+		close(done)
+	}()
+
+	err := col.Collect(client, metrics, labelValues)
+	close(metrics)
+	<-done
+
+	return recorded, err
+}
@@ -1,23 +1,32 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
 
 	"github.com/lwlcom/cisco_exporter/bgp"
 	"github.com/lwlcom/cisco_exporter/collector"
 	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/lwlcom/cisco_exporter/connections"
 	"github.com/lwlcom/cisco_exporter/connector"
 	"github.com/lwlcom/cisco_exporter/environment"
 	"github.com/lwlcom/cisco_exporter/facts"
+	"github.com/lwlcom/cisco_exporter/failover"
 	"github.com/lwlcom/cisco_exporter/interfaces"
 	"github.com/lwlcom/cisco_exporter/inventory"
 	"github.com/lwlcom/cisco_exporter/nat64"
 	"github.com/lwlcom/cisco_exporter/neighbors"
 	"github.com/lwlcom/cisco_exporter/optics"
+	"github.com/lwlcom/cisco_exporter/vpn"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// collectorNames are the names of all collectors, as used under features
+var collectorNames = []string{"bgp", "environment", "facts", "interfaces", "nat64", "neighbors", "optics", "inventory", "failover", "connections", "vpn"}
+
 type collectors struct {
 	collectors map[string]collector.RPCCollector
+	features   map[collector.RPCCollector]string
 	devices    map[string][]collector.RPCCollector
 	cfg        *config.Config
 }
@@ -25,12 +34,13 @@ type collectors struct {
 func collectorsForDevices(devices []*connector.Device, cfg *config.Config) *collectors {
 	c := &collectors{
 		collectors: make(map[string]collector.RPCCollector),
+		features:   make(map[collector.RPCCollector]string),
 		devices:    make(map[string][]collector.RPCCollector),
 		cfg:        cfg,
 	}
 
 	for _, d := range devices {
-		c.initCollectorsForDevice(d, deviceInterfaceRegex(cfg, d.Host))
+		c.initCollectorsForDevice(d, deviceInterfaceRegex(cfg, d))
 	}
 
 	return c
@@ -38,6 +48,9 @@ func collectorsForDevices(devices []*connector.Device, cfg *config.Config) *coll
 
 func (c *collectors) initCollectorsForDevice(device *connector.Device, descRe *regexp.Regexp) {
 	f := c.cfg.FeaturesForDevice(device.Host)
+	if device.Module != "" && device.DeviceConfig.Features != nil {
+		f = device.DeviceConfig.Features
+	}
 
 	c.devices[device.Host] = make([]collector.RPCCollector, 0)
 	c.addCollectorIfEnabledForDevice(device, "bgp", f.BGP, bgp.NewCollector)
@@ -50,6 +63,9 @@ func (c *collectors) initCollectorsForDevice(device *connector.Device, descRe *r
 	c.addCollectorIfEnabledForDevice(device, "neighbors", f.Neighbors, neighbors.NewCollector)
 	c.addCollectorIfEnabledForDevice(device, "optics", f.Optics, optics.NewCollector)
 	c.addCollectorIfEnabledForDevice(device, "inventory", f.Inventory, inventory.NewCollector)
+	c.addCollectorIfEnabledForDevice(device, "failover", f.Failover, failover.NewCollector)
+	c.addCollectorIfEnabledForDevice(device, "connections", f.Connections, connections.NewCollector)
+	c.addCollectorIfEnabledForDevice(device, "vpn", f.VPN, vpn.NewCollector)
 
 }
 
@@ -62,6 +78,7 @@ func (c *collectors) addCollectorIfEnabledForDevice(device *connector.Device, ke
 	if !found {
 		col = newCollector()
 		c.collectors[key] = col
+		c.features[col] = key
 	}
 
 	c.devices[device.Host] = append(c.devices[device.Host], col)
@@ -87,3 +104,81 @@ func (c *collectors) collectorsForDevice(device *connector.Device) []collector.R
 
 	return cols
 }
+
+// feature returns the name of the feature col is enabled by
+func (c *collectors) feature(col collector.RPCCollector) string {
+	return c.features[col]
+}
+
+// collectorFilter narrows the collectors run for a request, nil runs all
+type collectorFilter struct {
+	include map[string]bool
+	exclude map[string]bool
+}
+
+// newCollectorFilter creates a filter running the collectors in include (all if empty) except the ones in exclude
+func newCollectorFilter(include, exclude []string) (*collectorFilter, error) {
+	f := &collectorFilter{
+		exclude: make(map[string]bool),
+	}
+
+	known := make(map[string]bool)
+	for _, name := range collectorNames {
+		known[name] = true
+	}
+
+	for _, name := range include {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown collector %q", name)
+		}
+		if f.include == nil {
+			f.include = make(map[string]bool)
+		}
+		f.include[name] = true
+	}
+
+	for _, name := range exclude {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown collector %q", name)
+		}
+		f.exclude[name] = true
+	}
+
+	return f, nil
+}
+
+func (f *collectorFilter) includes(feature string) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.include != nil && !f.include[feature] {
+		return false
+	}
+
+	return !f.exclude[feature]
+}
+
+// taggedMetric is a metric of the collector enabled by feature
+type taggedMetric struct {
+	prometheus.Metric
+	feature string
+}
+
+// tagMetrics returns a channel tagging the metrics sent to it with feature before passing them to ch.
+// done has to be called after the last metric was sent.
+func tagMetrics(ch chan<- prometheus.Metric, feature string) (tagged chan<- prometheus.Metric, done func()) {
+	c := make(chan prometheus.Metric)
+	finished := make(chan struct{})
+	go func() {
+		for m := range c {
+			ch <- &taggedMetric{Metric: m, feature: feature}
+		}
+		close(finished)
+	}()
+
+	return c, func() {
+		close(c)
+		<-finished
+	}
+}
@@ -11,18 +11,45 @@ import (
 
 // Config represents the configuration for the exporter
 type Config struct {
-	Debug         bool            `yaml:"debug"`
-	LegacyCiphers bool            `yaml:"legacy_ciphers,omitempty"`
-	Timeout       int             `yaml:"timeout,omitempty"`
-	BatchSize     int             `yaml:"batch_size,omitempty"`
-	Username      string          `yaml:"username,omitempty"`
-	Password      string          `yaml:"Password,omitempty"`
-	KeyFile       string          `yaml:"key_file,omitempty"`
-	Devices       []*DeviceConfig `yaml:"devices,omitempty"`
-	Features      *FeatureConfig  `yaml:"features,omitempty"`
-	DynamicLabels bool            `yaml:"dynamic_labels,omitempty"`
-	IfDescRegStr  string          `yaml:"description_regex,omitempty"`
-	IfDescReg     *regexp.Regexp  `yaml:"-"`
+	Debug                 bool                     `yaml:"debug"`
+	LegacyCiphers         bool                     `yaml:"legacy_ciphers,omitempty"`
+	Timeout               int                      `yaml:"timeout,omitempty"`
+	BatchSize             int                      `yaml:"batch_size,omitempty"`
+	Username              string                   `yaml:"username,omitempty"`
+	Password              string                   `yaml:"Password,omitempty"`
+	KeyFile               string                   `yaml:"key_file,omitempty"`
+	KeyPassphrase         string                   `yaml:"key_passphrase,omitempty"`
+	KeyPassphraseFile     string                   `yaml:"key_passphrase_file,omitempty"`
+	SSHAgent              bool                     `yaml:"ssh_agent,omitempty"`
+	AuthMethods           []string                 `yaml:"auth_methods,omitempty"`
+	EnablePassword        string                   `yaml:"enable_password,omitempty"`
+	KnownHostsFile        string                   `yaml:"known_hosts_file,omitempty"`
+	TrustOnFirstUse       bool                     `yaml:"trust_on_first_use,omitempty"`
+	SkipHostKeyVerify     bool                     `yaml:"insecure_skip_host_key_verify,omitempty"`
+	JumpHost              *JumpHostConfig          `yaml:"jump_host,omitempty"`
+	PersistentConnections bool                     `yaml:"persistent_connections,omitempty"`
+	IdleTimeout           int                      `yaml:"idle_timeout,omitempty"`
+	DeviceInfoMaxAge      int                      `yaml:"device_info_max_age,omitempty"`
+	CommandCache          *CommandCacheConfig      `yaml:"command_cache,omitempty"`
+	MinIntervals          map[string]int           `yaml:"min_interval,omitempty"`
+	Transport             string                   `yaml:"transport,omitempty"`
+	ReplayDirectory       string                   `yaml:"replay_directory,omitempty"`
+	NetconfPort           int                      `yaml:"netconf_port,omitempty"`
+	NXAPI                 *APIConfig               `yaml:"nxapi,omitempty"`
+	Restconf              *APIConfig               `yaml:"restconf,omitempty"`
+	Telemetry             *TelemetryConfig         `yaml:"telemetry,omitempty"`
+	GNMI                  *GNMIConfig              `yaml:"gnmi,omitempty"`
+	Concurrency           *ConcurrencyConfig       `yaml:"concurrency,omitempty"`
+	Recording             *RecordingConfig         `yaml:"recording,omitempty"`
+	Polling               *PollingConfig           `yaml:"polling,omitempty"`
+	Devices               []*DeviceConfig          `yaml:"devices,omitempty"`
+	Modules               map[string]*ModuleConfig `yaml:"modules,omitempty"`
+	Features              *FeatureConfig           `yaml:"features,omitempty"`
+	DynamicLabels         bool                     `yaml:"dynamic_labels,omitempty"`
+	IfDescRegStr          string                   `yaml:"description_regex,omitempty"`
+	IfDescReg             *regexp.Regexp           `yaml:"-"`
+	PromptRegexStr        string                   `yaml:"prompt_regex,omitempty"`
+	PromptRegex           *regexp.Regexp           `yaml:"-"`
 }
 
 func (c *Config) load(dynamicIfaceLabels bool) error {
@@ -35,6 +62,15 @@ func (c *Config) load(dynamicIfaceLabels bool) error {
 		c.IfDescReg = re
 	}
 
+	if c.PromptRegexStr != "" {
+		re, err := regexp.Compile(c.PromptRegexStr)
+		if err != nil {
+			return fmt.Errorf("unable to compile prompt regex %q: %w", c.PromptRegexStr, err)
+		}
+
+		c.PromptRegex = re
+	}
+
 	for _, d := range c.Devices {
 		if d.IfDescRegStr != "" && dynamicIfaceLabels {
 			re, err := regexp.Compile(d.IfDescRegStr)
@@ -44,6 +80,26 @@ func (c *Config) load(dynamicIfaceLabels bool) error {
 
 			d.IfDescReg = re
 		}
+
+		if d.PromptRegexStr != "" {
+			re, err := regexp.Compile(d.PromptRegexStr)
+			if err != nil {
+				return fmt.Errorf("unable to compile prompt regex %q: %w", d.PromptRegexStr, err)
+			}
+
+			d.PromptRegex = re
+		}
+	}
+
+	for name, m := range c.Modules {
+		if m.IfDescRegStr != "" && dynamicIfaceLabels {
+			re, err := regexp.Compile(m.IfDescRegStr)
+			if err != nil {
+				return fmt.Errorf("unable to compile interfce description regex %q of module %s: %w", m.IfDescRegStr, name, err)
+			}
+
+			m.IfDescReg = re
+		}
 	}
 
 	return nil
@@ -51,18 +107,116 @@ func (c *Config) load(dynamicIfaceLabels bool) error {
 
 // DeviceConfig is the config representation of 1 device
 type DeviceConfig struct {
-	Host          string         `yaml:"host"`
-	Username      *string        `yaml:"username,omitempty"`
-	Password      *string        `yaml:"password,omitempty"`
-	KeyFile       *string        `yaml:"key_file,omitempty"`
-	LegacyCiphers *bool          `yaml:"legacy_ciphers,omitempty"`
-	Timeout       *int           `yaml:"timeout,omitempty"`
-	BatchSize     *int           `yaml:"batch_size,omitempty"`
-	Features      *FeatureConfig `yaml:"features,omitempty"`
-	IfDescRegStr  string         `yaml:"description_regex,omitempty"`
-	IfDescReg     *regexp.Regexp `yaml:"-"`
-	IsHostPattern bool           `yaml:"host_pattern,omitempty"`
-	HostPattern   *regexp.Regexp
+	Host              string            `yaml:"host"`
+	Group             string            `yaml:"group,omitempty"`
+	Record            *bool             `yaml:"record,omitempty"`
+	Transport         string            `yaml:"transport,omitempty"`
+	ReplayDirectory   *string           `yaml:"replay_directory,omitempty"`
+	NetconfPort       *int              `yaml:"netconf_port,omitempty"`
+	NXAPI             *APIConfig        `yaml:"nxapi,omitempty"`
+	Restconf          *APIConfig        `yaml:"restconf,omitempty"`
+	TelemetryNode     *string           `yaml:"telemetry_node,omitempty"`
+	GNMI              *GNMIConfig       `yaml:"gnmi,omitempty"`
+	Username          *string           `yaml:"username,omitempty"`
+	Password          *string           `yaml:"password,omitempty"`
+	KeyFile           *string           `yaml:"key_file,omitempty"`
+	KeyPassphrase     *string           `yaml:"key_passphrase,omitempty"`
+	KeyPassphraseFile *string           `yaml:"key_passphrase_file,omitempty"`
+	SSHAgent          *bool             `yaml:"ssh_agent,omitempty"`
+	AuthMethods       []string          `yaml:"auth_methods,omitempty"`
+	EnablePassword    *string           `yaml:"enable_password,omitempty"`
+	KnownHostsFile    *string           `yaml:"known_hosts_file,omitempty"`
+	TrustOnFirstUse   *bool             `yaml:"trust_on_first_use,omitempty"`
+	SkipHostKeyVerify *bool             `yaml:"insecure_skip_host_key_verify,omitempty"`
+	JumpHost          *JumpHostConfig   `yaml:"jump_host,omitempty"`
+	LegacyCiphers     *bool             `yaml:"legacy_ciphers,omitempty"`
+	Timeout           *int              `yaml:"timeout,omitempty"`
+	BatchSize         *int              `yaml:"batch_size,omitempty"`
+	Features          *FeatureConfig    `yaml:"features,omitempty"`
+	MinIntervals      map[string]int    `yaml:"min_interval,omitempty"`
+	Commands          map[string]string `yaml:"commands,omitempty"`
+	IfDescRegStr      string            `yaml:"description_regex,omitempty"`
+	IfDescReg         *regexp.Regexp    `yaml:"-"`
+	PromptRegexStr    string            `yaml:"prompt_regex,omitempty"`
+	PromptRegex       *regexp.Regexp    `yaml:"-"`
+	IsHostPattern     bool              `yaml:"host_pattern,omitempty"`
+	HostPattern       *regexp.Regexp
+}
+
+// ModuleConfig is a named set of settings a scrape can select with the module parameter
+type ModuleConfig struct {
+	Features     *FeatureConfig      `yaml:"features,omitempty"`
+	Timeout      *int                `yaml:"timeout,omitempty"`
+	Commands     map[string]string   `yaml:"commands,omitempty"`
+	MinIntervals map[string]int      `yaml:"min_interval,omitempty"`
+	CommandCache *CommandCacheConfig `yaml:"command_cache,omitempty"`
+	IfDescRegStr string              `yaml:"description_regex,omitempty"`
+	IfDescReg    *regexp.Regexp      `yaml:"-"`
+}
+
+// JumpHostConfig is the config representation of a bastion host devices are reached through
+type JumpHostConfig struct {
+	Host          string          `yaml:"host"`
+	Port          int             `yaml:"port,omitempty"`
+	Username      string          `yaml:"username,omitempty"`
+	Password      string          `yaml:"password,omitempty"`
+	KeyFile       string          `yaml:"key_file,omitempty"`
+	KeyPassphrase string          `yaml:"key_passphrase,omitempty"`
+	SSHAgent      bool            `yaml:"ssh_agent,omitempty"`
+	JumpHost      *JumpHostConfig `yaml:"jump_host,omitempty"`
+}
+
+// APIConfig configures how an HTTP based API of devices (NX-API, RESTCONF) is reached
+type APIConfig struct {
+	Port               int    `yaml:"port,omitempty"`
+	Scheme             string `yaml:"scheme,omitempty"`
+	CAFile             string `yaml:"ca_file,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// TelemetryConfig configures the receiver for model-driven telemetry devices send using gRPC dial-out
+type TelemetryConfig struct {
+	ListenAddress string `yaml:"listen_address,omitempty"`
+	MaxAge        int    `yaml:"max_age,omitempty"`
+	TLSCertFile   string `yaml:"tls_cert_file,omitempty"`
+	TLSKeyFile    string `yaml:"tls_key_file,omitempty"`
+}
+
+// GNMIConfig configures how the gNMI server of devices is reached and how data is retrieved
+type GNMIConfig struct {
+	Port               int    `yaml:"port,omitempty"`
+	Plaintext          bool   `yaml:"plaintext,omitempty"`
+	CAFile             string `yaml:"ca_file,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+	Mode               string `yaml:"mode,omitempty"`
+	Encoding           string `yaml:"encoding,omitempty"`
+	SampleInterval     int    `yaml:"sample_interval,omitempty"`
+}
+
+// ConcurrencyConfig limits the number of concurrent sessions to devices
+type ConcurrencyConfig struct {
+	MaxSessions int            `yaml:"max_sessions,omitempty"`
+	MaxWait     int            `yaml:"max_wait,omitempty"`
+	Groups      map[string]int `yaml:"groups,omitempty"`
+}
+
+// RecordingConfig configures where transcripts of device sessions are written to
+type RecordingConfig struct {
+	Enabled   bool   `yaml:"enabled,omitempty"`
+	Directory string `yaml:"directory,omitempty"`
+	MaxFiles  int    `yaml:"max_files,omitempty"`
+}
+
+// PollingConfig configures polling the devices in the background instead of on every scrape
+type PollingConfig struct {
+	Enabled  bool `yaml:"enabled,omitempty"`
+	Interval int  `yaml:"interval,omitempty"`
+	Jitter   int  `yaml:"jitter,omitempty"`
+}
+
+// CommandCacheConfig configures which command outputs are kept across scrapes
+type CommandCacheConfig struct {
+	TTL map[string]int `yaml:"ttl,omitempty"`
 }
 
 // FeatureConfig is the list of collectors enabled or disabled
@@ -75,12 +229,23 @@ type FeatureConfig struct {
 	Neighbors   *bool `yaml:"neighbors,omitempty"`
 	Optics      *bool `yaml:"optics,omitempty"`
 	Inventory   *bool `yaml:"inventory,omitempty"`
+	Failover    *bool `yaml:"failover,omitempty"`
+	Connections *bool `yaml:"connections,omitempty"`
+	VPN         *bool `yaml:"vpn,omitempty"`
 }
 
 // New creates a new config
 func New() *Config {
 	c := &Config{
-		Features: &FeatureConfig{},
+		Features:     &FeatureConfig{},
+		Concurrency:  &ConcurrencyConfig{},
+		Recording:    &RecordingConfig{},
+		CommandCache: &CommandCacheConfig{},
+		Polling:      &PollingConfig{},
+		NXAPI:        &APIConfig{},
+		Restconf:     &APIConfig{},
+		Telemetry:    &TelemetryConfig{},
+		GNMI:         &GNMIConfig{},
 	}
 	c.setDefaultValues()
 
@@ -116,40 +281,74 @@ func Load(reader io.Reader) (*Config, error) {
 		if d.Features == nil {
 			continue
 		}
-		if d.Features.BGP == nil {
-			d.Features.BGP = c.Features.BGP
-		}
-		if d.Features.Environment == nil {
-			d.Features.Environment = c.Features.Environment
-		}
-		if d.Features.Facts == nil {
-			d.Features.Facts = c.Features.Facts
-		}
-		if d.Features.Interfaces == nil {
-			d.Features.Interfaces = c.Features.Interfaces
-		}
-		if d.Features.Nat64 == nil {
-			d.Features.Nat64 = c.Features.Nat64
-		}
-		if d.Features.Neighbors == nil {
-			d.Features.Neighbors = c.Features.Neighbors
-		}
-		if d.Features.Optics == nil {
-			d.Features.Optics = c.Features.Optics
-		}
-		if d.Features.Inventory == nil {
-			d.Features.Inventory = c.Features.Inventory
+		d.Features.inherit(c.Features)
+	}
+
+	for _, m := range c.Modules {
+		if m.Features != nil {
+			m.Features.inherit(c.Features)
 		}
 	}
 
 	return c, nil
 }
 
+// inherit sets the features not configured in f to the ones in parent
+func (f *FeatureConfig) inherit(parent *FeatureConfig) {
+	if f.BGP == nil {
+		f.BGP = parent.BGP
+	}
+	if f.Environment == nil {
+		f.Environment = parent.Environment
+	}
+	if f.Facts == nil {
+		f.Facts = parent.Facts
+	}
+	if f.Interfaces == nil {
+		f.Interfaces = parent.Interfaces
+	}
+	if f.Nat64 == nil {
+		f.Nat64 = parent.Nat64
+	}
+	if f.Neighbors == nil {
+		f.Neighbors = parent.Neighbors
+	}
+	if f.Optics == nil {
+		f.Optics = parent.Optics
+	}
+	if f.Inventory == nil {
+		f.Inventory = parent.Inventory
+	}
+	if f.Failover == nil {
+		f.Failover = parent.Failover
+	}
+	if f.Connections == nil {
+		f.Connections = parent.Connections
+	}
+	if f.VPN == nil {
+		f.VPN = parent.VPN
+	}
+}
+
 func (c *Config) setDefaultValues() {
 	c.Debug = false
 	c.LegacyCiphers = false
 	c.Timeout = 5
 	c.BatchSize = 10000
+	c.IdleTimeout = 300
+	c.DeviceInfoMaxAge = 3600
+	c.NetconfPort = 830
+	c.NXAPI.Scheme = "https"
+	c.Restconf.Scheme = "https"
+	c.Telemetry.MaxAge = 180
+	c.GNMI.Port = 9339
+	c.GNMI.Mode = "subscribe"
+	c.GNMI.Encoding = "json_ietf"
+	c.GNMI.SampleInterval = 30
+	c.Concurrency.MaxWait = 10
+	c.Recording.MaxFiles = 10
+	c.Polling.Interval = 60
+	c.Polling.Jitter = 5
 	c.DynamicLabels = true
 
 	f := c.Features
@@ -169,6 +368,12 @@ func (c *Config) setDefaultValues() {
 	f.Optics = &optics
 	inventory := false
 	f.Inventory = &inventory
+	failover := false
+	f.Failover = &failover
+	connections := false
+	f.Connections = &connections
+	vpn := false
+	f.VPN = &vpn
 }
 
 // DevicesFromTargets creates devices configs from targets list
@@ -183,6 +388,24 @@ func (c *Config) DevicesFromTargets(sshHosts string) {
 	}
 }
 
+// KnownHostsFileForDevice returns the known hosts file the host keys of device are verified against, empty if none is set
+func (c *Config) KnownHostsFileForDevice(device *DeviceConfig) string {
+	if device.KnownHostsFile != nil {
+		return *device.KnownHostsFile
+	}
+
+	return c.KnownHostsFile
+}
+
+// SkipsHostKeyVerify returns if the host keys of device are not verified
+func (c *Config) SkipsHostKeyVerify(device *DeviceConfig) bool {
+	if device.SkipHostKeyVerify != nil {
+		return *device.SkipHostKeyVerify
+	}
+
+	return c.SkipHostKeyVerify
+}
+
 // FeaturesForDevice gets the feature set configured for a device
 func (c *Config) FeaturesForDevice(host string) *FeatureConfig {
 	d := c.FindDeviceConfig(host)
@@ -194,6 +417,57 @@ func (c *Config) FeaturesForDevice(host string) *FeatureConfig {
 	return c.Features
 }
 
+// MinIntervalForDevice gets the seconds the results of a collector (by feature name) are reused for a device scraped with
+// module (empty for none), 0 if it runs on every scrape
+func (c *Config) MinIntervalForDevice(host, module, feature string) int {
+	if m, found := c.Modules[module]; found {
+		if i, found := m.MinIntervals[feature]; found {
+			return i
+		}
+	}
+
+	d := c.FindDeviceConfig(host)
+
+	if d != nil {
+		if i, found := d.MinIntervals[feature]; found {
+			return i
+		}
+	}
+
+	return c.MinIntervals[feature]
+}
+
+// DeviceConfigForModule returns a copy of the device config d with the settings of the module name applied
+func (c *Config) DeviceConfigForModule(d *DeviceConfig, name string) (*DeviceConfig, error) {
+	m, found := c.Modules[name]
+	if !found {
+		return nil, fmt.Errorf("unknown module %q", name)
+	}
+
+	dc := *d
+	if m.Features != nil {
+		dc.Features = m.Features
+	}
+	if m.Timeout != nil {
+		dc.Timeout = m.Timeout
+	}
+	if m.IfDescReg != nil {
+		dc.IfDescRegStr = m.IfDescRegStr
+		dc.IfDescReg = m.IfDescReg
+	}
+	if len(m.Commands) > 0 {
+		dc.Commands = make(map[string]string)
+		for cmd, replacement := range d.Commands {
+			dc.Commands[cmd] = replacement
+		}
+		for cmd, replacement := range m.Commands {
+			dc.Commands[cmd] = replacement
+		}
+	}
+
+	return &dc, nil
+}
+
 func (c *Config) FindDeviceConfig(host string) *DeviceConfig {
 	for _, dc := range c.Devices {
 		if dc.HostPattern != nil {
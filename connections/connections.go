@@ -0,0 +1,16 @@
+package connections
+
+// ConnCount is the number of connections in use of a context
+type ConnCount struct {
+	InUse    float64
+	MostUsed float64
+}
+
+// Resource is the usage of a resource of a context. Limit is -1 if there is none.
+type Resource struct {
+	Name    string
+	Current float64
+	Peak    float64
+	Limit   float64
+	Denied  float64
+}
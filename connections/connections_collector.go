@@ -0,0 +1,103 @@
+package connections
+
+import (
+	"log"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+
+	"github.com/lwlcom/cisco_exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "cisco_connections_"
+
+var (
+	inUseDesc           *prometheus.Desc
+	mostUsedDesc        *prometheus.Desc
+	resourceCurrentDesc *prometheus.Desc
+	resourcePeakDesc    *prometheus.Desc
+	resourceLimitDesc   *prometheus.Desc
+	resourceDeniedDesc  *prometheus.Desc
+)
+
+func init() {
+	l := []string{"target", "context"}
+	inUseDesc = prometheus.NewDesc(prefix+"in_use", "Connections in use", l, nil)
+	mostUsedDesc = prometheus.NewDesc(prefix+"most_used", "Most connections used at the same time", l, nil)
+
+	l = append(l, "resource")
+	resourceCurrentDesc = prometheus.NewDesc(prefix+"resource_current", "Current usage of the resource", l, nil)
+	resourcePeakDesc = prometheus.NewDesc(prefix+"resource_peak", "Peak usage of the resource", l, nil)
+	resourceLimitDesc = prometheus.NewDesc(prefix+"resource_limit", "Limit of the resource (only if limited)", l, nil)
+	resourceDeniedDesc = prometheus.NewDesc(prefix+"resource_denied_total", "Number of times the resource was denied because of the limit", l, nil)
+}
+
+type connectionsCollector struct {
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	return &connectionsCollector{}
+}
+
+// Name returns the name of the collector
+func (*connectionsCollector) Name() string {
+	return "Connections"
+}
+
+// Describe describes the metrics
+func (*connectionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- inUseDesc
+	ch <- mostUsedDesc
+	ch <- resourceCurrentDesc
+	ch <- resourcePeakDesc
+	ch <- resourceLimitDesc
+	ch <- resourceDeniedDesc
+}
+
+// Collect collects metrics from Cisco. On ASAs in multiple context mode all contexts are walked.
+func (c *connectionsCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	return client.ForEachASAContext(func(context string) error {
+		return c.collectForContext(client, ch, append(labelValues, context))
+	})
+}
+
+func (c *connectionsCollector) collectForContext(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	out, err := client.RunCommand("show conn count")
+	if err != nil {
+		return err
+	}
+	count, err := c.ParseConnCount(client.OSType, out)
+	if err != nil {
+		if client.Debug {
+			log.Printf("ParseConnCount for %s: %s\n", labelValues[0], err.Error())
+		}
+	} else {
+		ch <- prometheus.MustNewConstMetric(inUseDesc, prometheus.GaugeValue, count.InUse, labelValues...)
+		ch <- prometheus.MustNewConstMetric(mostUsedDesc, prometheus.GaugeValue, count.MostUsed, labelValues...)
+	}
+
+	out, err = client.RunCommand("show resource usage")
+	if err != nil {
+		return err
+	}
+	resources, err := c.ParseResourceUsage(client.OSType, out)
+	if err != nil {
+		if client.Debug {
+			log.Printf("ParseResourceUsage for %s: %s\n", labelValues[0], err.Error())
+		}
+		return nil
+	}
+
+	for _, r := range resources {
+		l := append(labelValues, r.Name)
+		ch <- prometheus.MustNewConstMetric(resourceCurrentDesc, prometheus.GaugeValue, r.Current, l...)
+		ch <- prometheus.MustNewConstMetric(resourcePeakDesc, prometheus.GaugeValue, r.Peak, l...)
+		if r.Limit >= 0 {
+			ch <- prometheus.MustNewConstMetric(resourceLimitDesc, prometheus.GaugeValue, r.Limit, l...)
+		}
+		ch <- prometheus.MustNewConstMetric(resourceDeniedDesc, prometheus.CounterValue, r.Denied, l...)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package connections
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+	"github.com/lwlcom/cisco_exporter/util"
+)
+
+// ParseConnCount parses cli output of show conn count
+func (c *connectionsCollector) ParseConnCount(ostype string, output string) (*ConnCount, error) {
+	if ostype != rpc.ASA {
+		return nil, errors.New("'show conn count' is not implemented for " + ostype)
+	}
+	// 1234 in use, 56789 most used
+	connCountRegexp := regexp.MustCompile(`^\s*(\d+) in use, (\d+) most used`)
+	for _, line := range strings.Split(output, "\n") {
+		matches := connCountRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		return &ConnCount{
+			InUse:    util.Str2float64(matches[1]),
+			MostUsed: util.Str2float64(matches[2]),
+		}, nil
+	}
+	return nil, errors.New("Connection count not found")
+}
+
+// ParseResourceUsage parses cli output of show resource usage
+func (c *connectionsCollector) ParseResourceUsage(ostype string, output string) ([]Resource, error) {
+	if ostype != rpc.ASA {
+		return nil, errors.New("'show resource usage' is not implemented for " + ostype)
+	}
+	// Resource              Current        Peak      Limit        Denied Context
+	// Conns [rate]                3          57        N/A             0 admin
+	// Conns                     123        4567   unlimited             0 admin
+	resourceRegexp := regexp.MustCompile(`^(\S.*?)\s+(\d+)\s+(\d+)\s+(\d+|N/A|unlimited)\s+(\d+)\s+\S+\s*$`)
+	items := []Resource{}
+	for _, line := range strings.Split(output, "\n") {
+		matches := resourceRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		r := Resource{
+			Name:    matches[1],
+			Current: util.Str2float64(matches[2]),
+			Peak:    util.Str2float64(matches[3]),
+			Limit:   -1,
+			Denied:  util.Str2float64(matches[5]),
+		}
+		if matches[4] != "N/A" && matches[4] != "unlimited" {
+			r.Limit = util.Str2float64(matches[4])
+		}
+		items = append(items, r)
+	}
+	return items, nil
+}
@@ -0,0 +1,245 @@
+package connector
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
+)
+
+var (
+	promptRegexp           = regexp.MustCompile(`.+[#>]\s?$`)
+	privilegedPromptRegexp = regexp.MustCompile(`.+#\s?$`)
+	userExecPromptRegexp   = regexp.MustCompile(`.+>\s?$`)
+	passwordPromptRegexp   = regexp.MustCompile(`(?i)password:\s?$`)
+	enablePromptRegexp     = regexp.MustCompile(`(?i)(.+[#>]|password:)\s?$`)
+	promptHostnameRegexp   = regexp.MustCompile(`^([^\s#>(]+)(?:\([^)]*\))?[#>]$`)
+	iosxrNodePromptRegexp  = regexp.MustCompile(`^RP/\d+/[^/]+/CPU\d+:(.+)$`)
+	backspaceRegexp        = regexp.MustCompile(`\x08+ *\x08*`)
+)
+
+// pagerAnswers are sent when the output of a command stops at one of these prompts
+var pagerAnswers = []struct {
+	re     *regexp.Regexp
+	answer string
+}{
+	{re: regexp.MustCompile(`\s?--More--\s?$`), answer: " "},
+	{re: regexp.MustCompile(`\s?<--- More --->\s?$`), answer: " "},
+	{re: regexp.MustCompile(`\[confirm\]\s?$`), answer: "\n"},
+}
+
+// cli runs commands on the command line interface of a device, independent of the transport
+type cli struct {
+	host           string
+	stdin          io.Writer
+	stdout         io.Reader
+	batchSize      int
+	timeout        time.Duration
+	enablePassword string
+	prompt         *regexp.Regexp
+	closer         io.Closer
+	broken         bool
+}
+
+func cliForDevice(device *Device, cfg *config.Config) cli {
+	deviceConfig := device.DeviceConfig
+
+	batchSize := cfg.BatchSize
+	if deviceConfig.BatchSize != nil {
+		batchSize = *deviceConfig.BatchSize
+	}
+
+	enablePassword := cfg.EnablePassword
+	if deviceConfig.EnablePassword != nil {
+		enablePassword = *deviceConfig.EnablePassword
+	}
+
+	timeout := cfg.Timeout
+	if deviceConfig.Timeout != nil {
+		timeout = *deviceConfig.Timeout
+	}
+
+	prompt := cfg.PromptRegex
+	if deviceConfig.PromptRegex != nil {
+		prompt = deviceConfig.PromptRegex
+	}
+
+	return cli{
+		host:           device.Host,
+		batchSize:      batchSize,
+		timeout:        time.Duration(timeout) * time.Second,
+		enablePassword: enablePassword,
+		prompt:         prompt,
+	}
+}
+
+type result struct {
+	output string
+	err    error
+}
+
+// init prepares the CLI for running commands after login
+func (c *cli) init(ctx context.Context) error {
+	out, _ := c.RunCommand(ctx, "")
+	if c.prompt == nil {
+		c.prompt = learnPrompt(out)
+	}
+
+	if userExecPromptRegexp.MatchString(out) && c.enablePassword != "" {
+		err := c.enable(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	out, err := c.RunCommand(ctx, "terminal length 0")
+	if err == nil && strings.Contains(out, "Invalid input") {
+		// ASA sets the lines of the pager using terminal pager
+		_, err = c.RunCommand(ctx, "terminal pager 0")
+	}
+
+	return err
+}
+
+// enable enters privileged exec mode
+func (c *cli) enable(ctx context.Context) error {
+	out, err := c.send(ctx, "enable", "enable", enablePromptRegexp)
+	if err != nil {
+		return errors.Wrap(err, "could not run enable")
+	}
+
+	if passwordPromptRegexp.MatchString(out) {
+		// the password is not echoed back
+		out, err = c.send(ctx, c.enablePassword, "", enablePromptRegexp)
+		if err != nil {
+			return errors.Wrap(err, "could not send enable password")
+		}
+	}
+
+	if !privilegedPromptRegexp.MatchString(out) {
+		return errors.Errorf("could not enter privileged exec mode on %s: %s", c.host, strings.TrimSpace(out))
+	}
+
+	return nil
+}
+
+// RunCommand runs a command against the device
+func (c *cli) RunCommand(ctx context.Context, cmd string) (string, error) {
+	prompt := c.prompt
+	if prompt == nil {
+		prompt = promptRegexp
+	}
+
+	return c.send(ctx, cmd, cmd, prompt)
+}
+
+// RunCommandExpect runs a command against the device and reads its output until prompt matches
+func (c *cli) RunCommandExpect(ctx context.Context, cmd string, prompt *regexp.Regexp) (string, error) {
+	return c.send(ctx, cmd, cmd, prompt)
+}
+
+// learnPrompt returns a regex matching the prompt in the last line of output in all modes of the CLI.
+// It returns nil if the last line does not look like a prompt.
+// IOS XR prefixes the hostname with the active route processor (e.g. RP/0/RSP0/CPU0:host#), which changes on failover.
+// ASA appends the security context and failover state (e.g. host/ctx1/act#), which change using changeto context.
+func learnPrompt(output string) *regexp.Regexp {
+	lines := strings.Split(output, "\n")
+	m := promptHostnameRegexp.FindStringSubmatch(strings.TrimSpace(lines[len(lines)-1]))
+	if m == nil {
+		return nil
+	}
+
+	host := regexp.QuoteMeta(m[1])
+	if xr := iosxrNodePromptRegexp.FindStringSubmatch(m[1]); xr != nil {
+		host = `RP/\d+/[^/\s]+/CPU\d+:` + regexp.QuoteMeta(xr[1])
+	} else if i := strings.Index(m[1], "/"); i > 0 {
+		host = regexp.QuoteMeta(m[1][:i])
+	}
+
+	return regexp.MustCompile(`(?:^|\n)` + host + `(?:/[^\s/#>(]+)*(?:\([^)\n]*\))?[#>]\s?$`)
+}
+
+// send writes input to the device and reads until the output contains echo and matches re
+func (c *cli) send(ctx context.Context, input, echo string, re *regexp.Regexp) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	io.WriteString(c.stdin, input+"\n")
+
+	return c.expect(ctx, echo, re)
+}
+
+// expect reads until the output contains echo and matches re
+func (c *cli) expect(ctx context.Context, echo string, re *regexp.Regexp) (string, error) {
+	buf := bufio.NewReader(c.stdout)
+
+	outputChan := make(chan result, 1)
+	go func() {
+		c.readln(outputChan, echo, re, buf)
+	}()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-outputChan:
+		if res.err != nil {
+			c.broken = true
+		}
+		return res.output, res.err
+	case <-timer.C:
+		c.abort()
+		return "", errors.New("Timeout reached")
+	case <-ctx.Done():
+		c.abort()
+		return "", ctx.Err()
+	}
+}
+
+// abort closes the transport so a pending read returns. The connection can not be used afterwards.
+func (c *cli) abort() {
+	c.broken = true
+	if c.closer != nil {
+		c.closer.Close()
+	}
+}
+
+// answerPager answers a pager or confirmation prompt at the end of output and removes it
+func (c *cli) answerPager(output string) string {
+	for _, p := range pagerAnswers {
+		loc := p.re.FindStringIndex(output)
+		if loc == nil {
+			continue
+		}
+
+		io.WriteString(c.stdin, p.answer)
+		return output[:loc[0]]
+	}
+
+	return output
+}
+
+func (c *cli) readln(ch chan result, cmd string, re *regexp.Regexp, r io.Reader) {
+	buf := make([]byte, c.batchSize)
+	loadStr := ""
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			ch <- result{output: "", err: err}
+			return
+		}
+		loadStr += string(buf[:n])
+		if strings.Contains(loadStr, cmd) && re.MatchString(loadStr) {
+			break
+		}
+		loadStr = c.answerPager(loadStr)
+	}
+	loadStr = strings.Replace(loadStr, "\r", "", -1)
+	loadStr = backspaceRegexp.ReplaceAllString(loadStr, "")
+	ch <- result{output: loadStr, err: nil}
+}
@@ -1,21 +1,46 @@
 package connector
 
 import (
-	"bufio"
+	"context"
 	"io"
 	"io/ioutil"
-	"regexp"
+	"net"
 	"strings"
-
 	"time"
 
 	"github.com/lwlcom/cisco_exporter/config"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
 )
 
 // NewSSSHConnection connects to device
-func NewSSSHConnection(device *Device, cfg *config.Config) (*SSHConnection, error) {
+func NewSSSHConnection(ctx context.Context, device *Device, cfg *config.Config) (*SSHConnection, error) {
+	cli := cliForDevice(device, cfg)
+
+	sshConfig, err := sshClientConfig(device, cfg, cli.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &SSHConnection{
+		Host:         device.Host + ":" + device.Port,
+		clientConfig: sshConfig,
+		jumpHost:     device.JumpHost,
+		auth:         device.Auth,
+		cli:          cli,
+	}
+
+	err = c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// sshClientConfig returns the settings besides authentication used to connect to device
+func sshClientConfig(device *Device, cfg *config.Config, timeout time.Duration) (*ssh.ClientConfig, error) {
 	deviceConfig := device.DeviceConfig
 
 	legacyCiphers := cfg.LegacyCiphers
@@ -23,63 +48,53 @@ func NewSSSHConnection(device *Device, cfg *config.Config) (*SSHConnection, erro
 		legacyCiphers = *deviceConfig.LegacyCiphers
 	}
 
-	batchSize := cfg.BatchSize
-	if deviceConfig.BatchSize != nil {
-		batchSize = *deviceConfig.BatchSize
+	trustOnFirstUse := cfg.TrustOnFirstUse
+	if deviceConfig.TrustOnFirstUse != nil {
+		trustOnFirstUse = *deviceConfig.TrustOnFirstUse
 	}
 
-	timeout := cfg.Timeout
-	if deviceConfig.Timeout != nil {
-		timeout = *deviceConfig.Timeout
+	hostKeyCallback, err := hostKeyCallback(cfg.KnownHostsFileForDevice(deviceConfig), trustOnFirstUse, cfg.SkipsHostKeyVerify(deviceConfig))
+	if err != nil {
+		return nil, err
 	}
 
 	sshConfig := &ssh.ClientConfig{
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         time.Duration(timeout) * time.Second,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
 	}
 	if legacyCiphers {
 		sshConfig.SetDefaults()
 		sshConfig.Ciphers = append(sshConfig.Ciphers, "aes128-cbc", "3des-cbc")
 	}
 
-	device.Auth(sshConfig)
-
-	c := &SSHConnection{
-		Host:         device.Host + ":" + device.Port,
-		batchSize:    batchSize,
-		clientConfig: sshConfig,
-	}
-
-	err := c.Connect()
-	if err != nil {
-		return nil, err
-	}
-
-	return c, nil
+	return sshConfig, nil
 }
 
 // SSHConnection encapsulates the connection to the device
 type SSHConnection struct {
+	cli
 	client       *ssh.Client
 	Host         string
-	stdin        io.WriteCloser
-	stdout       io.Reader
 	session      *ssh.Session
-	batchSize    int
 	clientConfig *ssh.ClientConfig
+	jumpHost     *JumpHost
+	jump         *jumpConnection
+	auth         []AuthMethod
 }
 
 // Connect connects to the device
-func (c *SSHConnection) Connect() error {
+func (c *SSHConnection) Connect(ctx context.Context) error {
 	var err error
-	c.client, err = ssh.Dial("tcp", c.Host, c.clientConfig)
+	c.client, c.jump, err = connectSSH(ctx, c.Host, c.clientConfig, c.jumpHost, c.auth)
 	if err != nil {
 		return err
 	}
+	c.closer = c.client
 
 	session, err := c.client.NewSession()
 	if err != nil {
 		c.client.Conn.Close()
+		c.releaseJumpConnection()
 		return err
 	}
 	c.stdin, _ = session.StdinPipe()
@@ -92,32 +107,232 @@ func (c *SSHConnection) Connect() error {
 	session.Shell()
 	c.session = session
 
-	c.RunCommand("")
-	c.RunCommand("terminal length 0")
+	err = c.init(ctx)
+	if err != nil {
+		c.Close()
+		return err
+	}
 
 	return nil
 }
 
-type result struct {
-	output string
-	err    error
-}
+// Alive checks if the connection can still be used to run commands
+func (c *SSHConnection) Alive() bool {
+	if c.broken || c.client == nil {
+		return false
+	}
 
-// RunCommand runs a command against the device
-func (c *SSHConnection) RunCommand(cmd string) (string, error) {
-	buf := bufio.NewReader(c.stdout)
-	io.WriteString(c.stdin, cmd+"\n")
+	return isAlive(c.client, c.clientConfig.Timeout)
+}
 
-	outputChan := make(chan result)
+func isAlive(client *ssh.Client, timeout time.Duration) bool {
+	res := make(chan error, 1)
 	go func() {
-		c.readln(outputChan, cmd, buf)
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		res <- err
 	}()
+
 	select {
-	case res := <-outputChan:
-		return res.output, res.err
-	case <-time.After(c.clientConfig.Timeout):
-		return "", errors.New("Timeout reached")
+	case err := <-res:
+		return err == nil
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// connectSSH connects to addr, through the jump host if set. The returned jump connection has to be released after use.
+func connectSSH(ctx context.Context, addr string, config *ssh.ClientConfig, jumpHost *JumpHost, auth []AuthMethod) (*ssh.Client, *jumpConnection, error) {
+	var jc *jumpConnection
+	var via *ssh.Client
+	if jumpHost != nil {
+		var err error
+		jc, via, err = acquireJumpConnection(ctx, jumpHost, config)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	client, err := dialAuth(ctx, via, addr, config, auth)
+	if err != nil {
+		if jc != nil {
+			releaseJumpConnection(jc)
+		}
+		return nil, nil, err
+	}
+
+	return client, jc, nil
+}
+
+// dialAuth connects to addr trying the authentication methods in order. Methods sharing a username are tried
+// in one handshake, a new connection is only opened for the methods of another username.
+func dialAuth(ctx context.Context, via *ssh.Client, addr string, config *ssh.ClientConfig, methods []AuthMethod) (*ssh.Client, error) {
+	err := errors.New("no authentication method available")
+	for _, group := range authGroups(methods) {
+		var client *ssh.Client
+		var authenticating bool
+		client, authenticating, err = dialAuthGroup(ctx, via, addr, config, group)
+		if err == nil {
+			return client, nil
+		}
+
+		if !authenticating {
+			// the connection failed before any method was tried, the next ones would fail the same way
+			return nil, err
+		}
+	}
+
+	return nil, errors.Wrap(err, "all authentication methods failed")
+}
+
+// authGroups splits methods into the groups tried on one connection each. The methods of a group share the username.
+// As the ssh package does not try an SSH authentication method again after it failed, consecutive public key methods
+// are offered together and a method used before in the group starts a new one.
+func authGroups(methods []AuthMethod) [][]AuthMethod {
+	groups := make([][]AuthMethod, 0)
+	var group []AuthMethod
+	for _, m := range methods {
+		if len(group) > 0 && !fitsAuthGroup(group, m) {
+			groups = append(groups, group)
+			group = nil
+		}
+		group = append(group, m)
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
 	}
+
+	return groups
+}
+
+func fitsAuthGroup(group []AuthMethod, m AuthMethod) bool {
+	if group[0].username != m.username {
+		return false
+	}
+
+	if m.publicKey() && group[len(group)-1].publicKey() {
+		return true
+	}
+
+	for _, g := range group {
+		if g.publicKey() == m.publicKey() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dialAuthGroup connects to addr trying the methods of group in one handshake. The reason a method failed is logged
+// when the next one is tried. authenticating is false if the connection failed before any method was tried.
+func dialAuthGroup(ctx context.Context, via *ssh.Client, addr string, config *ssh.ClientConfig, group []AuthMethod) (client *ssh.Client, authenticating bool, err error) {
+	cfg := *config
+	cfg.User = group[0].username
+	cfg.Auth = nil
+
+	// tried holds the names of the methods tried last until their failure is logged
+	var tried string
+	try := func(names string) {
+		authenticating = true
+		if tried != "" {
+			log.Warnf("%s: authentication with %s failed: rejected by the device", addr, tried)
+		}
+		tried = names
+	}
+
+	for i := 0; i < len(group); {
+		if !group[i].publicKey() {
+			m := group[i]
+			cfg.Auth = append(cfg.Auth, ssh.PasswordCallback(func() (string, error) {
+				try(m.name)
+				return m.password, nil
+			}))
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(group) && group[j].publicKey() {
+			j++
+		}
+		keys := group[i:j]
+		cfg.Auth = append(cfg.Auth, ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			signers := make([]ssh.Signer, 0)
+			names := make([]string, 0)
+			for _, k := range keys {
+				s, err := k.signers()
+				if err != nil {
+					log.Warnf("%s: authentication with %s failed: %v", addr, k, err)
+					continue
+				}
+				signers = append(signers, s...)
+				names = append(names, k.name)
+			}
+
+			try(strings.Join(names, ", "))
+			if len(signers) == 0 {
+				return nil, errors.New("no keys available")
+			}
+
+			return signers, nil
+		}))
+		i = j
+	}
+
+	client, err = dial(ctx, via, addr, &cfg)
+	if err != nil && tried != "" {
+		log.Warnf("%s: authentication with %s failed: %v", addr, tried, err)
+	}
+
+	return client, authenticating, err
+}
+
+// dial connects to addr, through the jump host connection via if set.
+// Host key mismatches are preserved, the ssh package only reports them as text.
+func dial(ctx context.Context, via *ssh.Client, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	var mismatch *HostKeyMismatchError
+
+	cfg := *config
+	cfg.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := config.HostKeyCallback(hostname, remote, key)
+		errors.As(err, &mismatch)
+		return err
+	}
+
+	var conn net.Conn
+	var err error
+	if via != nil {
+		conn, err = via.Dial("tcp", addr)
+	} else {
+		d := &net.Dialer{Timeout: cfg.Timeout}
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// limit the handshake to the timeout and the deadline of the scrape
+	deadline := time.Now().Add(cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	defer conn.SetDeadline(time.Time{})
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &cfg)
+	if mismatch != nil {
+		conn.Close()
+		return nil, mismatch
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+func (c *SSHConnection) String() string {
+	return c.Host
 }
 
 // Close closes connection
@@ -129,36 +344,38 @@ func (c *SSHConnection) Close() {
 	if c.session != nil {
 		c.session.Close()
 	}
+	c.releaseJumpConnection()
+}
+
+func (c *SSHConnection) releaseJumpConnection() {
+	if c.jump == nil {
+		return
+	}
+
+	releaseJumpConnection(c.jump)
+	c.jump = nil
 }
 
-func loadPrivateKey(r io.Reader) (ssh.AuthMethod, error) {
+func loadPrivateKey(r io.Reader, passphrase []byte) (ssh.Signer, error) {
 	b, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not read from reader")
 	}
 
-	key, err := ssh.ParsePrivateKey(b)
+	var key ssh.Signer
+	if len(passphrase) > 0 {
+		key, err = ssh.ParsePrivateKeyWithPassphrase(b, passphrase)
+	} else {
+		key, err = ssh.ParsePrivateKey(b)
+	}
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, errors.New("private key is encrypted, but no passphrase is set")
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "could not parse private key")
 	}
 
-	return ssh.PublicKeys(key), nil
-}
-
-func (c *SSHConnection) readln(ch chan result, cmd string, r io.Reader) {
-	re := regexp.MustCompile(`.+#\s?$`)
-	buf := make([]byte, c.batchSize)
-	loadStr := ""
-	for {
-		n, err := r.Read(buf)
-		if err != nil {
-			ch <- result{output: "", err: err}
-		}
-		loadStr += string(buf[:n])
-		if strings.Contains(loadStr, cmd) && re.MatchString(loadStr) {
-			break
-		}
-	}
-	loadStr = strings.Replace(loadStr, "\r", "", -1)
-	ch <- result{output: loadStr, err: nil}
+	return key, nil
 }
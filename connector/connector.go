@@ -0,0 +1,67 @@
+package connector
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	TransportSSH       string = "ssh"
+	TransportTelnet    string = "telnet"
+	TransportReplay    string = "replay"
+	TransportNetconf   string = "netconf"
+	TransportNXAPI     string = "nxapi"
+	TransportRestconf  string = "restconf"
+	TransportTelemetry string = "telemetry"
+	TransportGNMI      string = "gnmi"
+)
+
+// Connection is a session to the CLI of a device, independent of the transport used
+type Connection interface {
+	// RunCommand runs a command against the device
+	RunCommand(ctx context.Context, cmd string) (string, error)
+
+	// RunCommandExpect runs a command against the device and reads its output until prompt matches
+	RunCommandExpect(ctx context.Context, cmd string, prompt *regexp.Regexp) (string, error)
+
+	// Alive checks if the connection can still be used to run commands
+	Alive() bool
+
+	// Close closes connection
+	Close()
+
+	// String returns the address of the device
+	String() string
+}
+
+// UsesSSH checks if devices using transport open SSH sessions, either as their transport or to fall back to the CLI
+func UsesSSH(transport string) bool {
+	switch transport {
+	case TransportSSH, TransportNetconf, TransportRestconf, TransportTelemetry, TransportGNMI, "":
+		return true
+	}
+
+	return false
+}
+
+// NewConnection connects to device using the transport configured for it
+func NewConnection(ctx context.Context, device *Device, cfg *config.Config) (Connection, error) {
+	if UsesSSH(device.Transport) {
+		// devices using NETCONF, RESTCONF, telemetry or gNMI fall back to the CLI using SSH for collectors without implementation for them
+		return NewSSSHConnection(ctx, device, cfg)
+	}
+
+	switch device.Transport {
+	case TransportTelnet:
+		return NewTelnetConnection(ctx, device, cfg)
+	case TransportReplay:
+		return NewReplayConnection(device, cfg)
+	case TransportNXAPI:
+		return NewNXAPIConnection(ctx, device, cfg)
+	}
+
+	return nil, errors.Errorf("unknown transport %q", device.Transport)
+}
@@ -2,42 +2,142 @@ package connector
 
 import (
 	"io"
+	"net"
+	"os"
+	"sync"
 
 	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 type Device struct {
-	Host         string
-	Port         string
-	Auth         AuthMethod
-	ClientConfig ssh.ClientConfig
-	DeviceConfig *config.DeviceConfig
+	Host          string
+	Port          string
+	NetconfPort   string
+	NXAPI         *config.APIConfig
+	Restconf      *config.APIConfig
+	TelemetryNode string
+	GNMI          *config.GNMIConfig
+	Transport     string
+	Username      string
+	Password      string
+	Auth          []AuthMethod
+	JumpHost      *JumpHost
+	ClientConfig  ssh.ClientConfig
+	DeviceConfig  *config.DeviceConfig
+
+	// Module is the name of the module applied to DeviceConfig, empty if the request did not select one
+	Module string
+}
+
+// AuthMethod is a method to use to authenticate agaist the device
+type AuthMethod struct {
+	name     string
+	username string
+	password string
+
+	// signers returns the keys for public key authentication, nil for password authentication
+	signers func() ([]ssh.Signer, error)
 }
 
-// AuthMethod is the method to use to authenticate agaist the device
-type AuthMethod func(*ssh.ClientConfig)
+// publicKey returns if the method uses public key authentication
+func (a AuthMethod) publicKey() bool {
+	return a.signers != nil
+}
+
+func (a AuthMethod) String() string {
+	return a.name
+}
 
 // AuthByPassword uses password authentication
 func AuthByPassword(username, password string) AuthMethod {
-	return func(cfg *ssh.ClientConfig) {
-		cfg.User = username
-		cfg.Auth = append(cfg.Auth, ssh.Password(password))
+	return AuthMethod{
+		name:     "password",
+		username: username,
+		password: password,
 	}
 }
 
 // AuthByKey uses public key authentication
 func AuthByKey(username string, key io.Reader) (AuthMethod, error) {
-	pk, err := loadPrivateKey(key)
+	return AuthByKeyWithPassphrase(username, key, nil)
+}
+
+// AuthByKeyWithPassphrase uses public key authentication with a passphrase protected key
+func AuthByKeyWithPassphrase(username string, key io.Reader, passphrase []byte) (AuthMethod, error) {
+	pk, err := loadPrivateKey(key, passphrase)
 	if err != nil {
-		return nil, err
+		return AuthMethod{}, err
 	}
-	return func(cfg *ssh.ClientConfig) {
-		cfg.User = username
-		cfg.Auth = append(cfg.Auth, pk)
+	return AuthMethod{
+		name:     "key",
+		username: username,
+		signers: func() ([]ssh.Signer, error) {
+			return []ssh.Signer{pk}, nil
+		},
 	}, nil
 }
 
+// AuthByAgent uses public key authentication with the keys held by the ssh-agent listening on SSH_AUTH_SOCK
+func AuthByAgent(username string) AuthMethod {
+	return AuthMethod{
+		name:     "agent",
+		username: username,
+		signers:  agentSigners,
+	}
+}
+
+var (
+	agentMu     sync.Mutex
+	agentConn   net.Conn
+	agentClient agent.ExtendedAgent
+)
+
+// agentSigners returns the keys of the ssh-agent. The connection to the agent is shared by all devices.
+func agentSigners() ([]ssh.Signer, error) {
+	agentMu.Lock()
+	defer agentMu.Unlock()
+
+	if agentClient != nil {
+		signers, err := agentClient.Signers()
+		if err == nil {
+			return signers, nil
+		}
+
+		agentConn.Close()
+		agentClient = nil
+	}
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, errors.New("SSH_AUTH_SOCK is not set")
+	}
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not connect to ssh agent")
+	}
+
+	client := agent.NewClient(conn)
+	signers, err := client.Signers()
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "could not get keys from ssh agent")
+	}
+
+	if len(signers) == 0 {
+		conn.Close()
+		return nil, errors.New("ssh agent holds no keys")
+	}
+
+	agentConn = conn
+	agentClient = client
+
+	return signers, nil
+}
+
 func (d *Device) String() string {
 	return d.Host
 }
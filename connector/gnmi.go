@@ -0,0 +1,432 @@
+package connector
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	GNMIModeSubscribe string = "subscribe"
+	GNMIModeGet       string = "get"
+)
+
+// gnmiSubscriptions are the openconfig paths subscribed to if the device supports the model
+var gnmiSubscriptions = []struct {
+	model string
+	path  string
+}{
+	{model: "openconfig-interfaces", path: "/interfaces/interface/state"},
+	{model: "openconfig-interfaces", path: "/interfaces/interface/ethernet/state"},
+	{model: "openconfig-platform", path: "/components/component"},
+	{model: "openconfig-network-instance", path: "/network-instances/network-instance/protocols/protocol/bgp/neighbors"},
+}
+
+var gnmiEncodings = map[string]pb.Encoding{
+	"json":      pb.Encoding_JSON,
+	"json_ietf": pb.Encoding_JSON_IETF,
+	"proto":     pb.Encoding_PROTO,
+}
+
+// GNMIManager holds the gNMI sessions of devices across scrapes, so subscriptions stay open between them.
+// Sessions which failed are replaced by a new one on the next scrape, which also reads the capabilities again.
+type GNMIManager struct {
+	mu       sync.Mutex
+	sessions map[string]*GNMISession
+	opts     []grpc.DialOption
+}
+
+// NewGNMIManager creates a new manager for gNMI sessions. opts are passed to the gRPC dial of each session.
+func NewGNMIManager(opts ...grpc.DialOption) *GNMIManager {
+	return &GNMIManager{sessions: make(map[string]*GNMISession), opts: opts}
+}
+
+// Session returns the gNMI session of device, which is created on first use.
+// In subscribe mode it waits until the device sent the initial values of all subscribed paths.
+func (m *GNMIManager) Session(ctx context.Context, device *Device, cfg *config.Config) (*GNMISession, error) {
+	s, err := m.session(ctx, device, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.waitForSync(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (m *GNMIManager) session(ctx context.Context, device *Device, cfg *config.Config) (*GNMISession, error) {
+	m.mu.Lock()
+	s, found := m.sessions[device.Host]
+	failed := found && s.failed()
+	if failed {
+		delete(m.sessions, device.Host)
+	}
+	m.mu.Unlock()
+
+	if found && !failed {
+		return s, nil
+	}
+
+	if failed {
+		if cfg.Debug {
+			log.Infof("%s: replacing failed gNMI session", device.Host)
+		}
+		s.Close()
+	}
+
+	s, err := NewGNMISession(ctx, device, cfg, m.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// another scrape might have connected in the meantime
+	if existing, found := m.sessions[device.Host]; found {
+		s.Close()
+		return existing, nil
+	}
+	m.sessions[device.Host] = s
+
+	return s, nil
+}
+
+// Close closes all sessions
+func (m *GNMIManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for host, s := range m.sessions {
+		s.Close()
+		delete(m.sessions, host)
+	}
+}
+
+// NewGNMISession connects to the gNMI server of device and reads the models it supports, which also verifies access.
+// In subscribe mode a subscription to the supported paths is held in the background until the session is closed.
+// opts are passed to the gRPC dial in addition to the transport credentials.
+func NewGNMISession(ctx context.Context, device *Device, cfg *config.Config, opts ...grpc.DialOption) (*GNMISession, error) {
+	gc := device.GNMI
+
+	encoding, found := gnmiEncodings[strings.ToLower(gc.Encoding)]
+	if !found {
+		return nil, errors.Errorf("unknown gNMI encoding %q", gc.Encoding)
+	}
+
+	if gc.Mode != GNMIModeSubscribe && gc.Mode != GNMIModeGet {
+		return nil, errors.Errorf("unknown gNMI mode %q", gc.Mode)
+	}
+
+	creds := insecure.NewCredentials()
+	if !gc.Plaintext {
+		tlsConfig, err := newTLSConfig(gc.CAFile, gc.InsecureSkipVerify)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not initialize gNMI client")
+		}
+
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	addr := net.JoinHostPort(device.Host, strconv.Itoa(gc.Port))
+	conn, err := grpc.DialContext(ctx, addr, append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, opts...)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not connect to gNMI server of %s", device.Host)
+	}
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	s := &GNMISession{
+		Host:           device.Host,
+		conn:           conn,
+		client:         pb.NewGNMIClient(conn),
+		username:       device.Username,
+		password:       device.Password,
+		encoding:       encoding,
+		mode:           gc.Mode,
+		sampleInterval: time.Duration(gc.SampleInterval) * time.Second,
+		models:         make(map[string]bool),
+		cache:          newGNMICache(),
+		synced:         make(chan struct{}),
+		cancel:         cancel,
+		debug:          cfg.Debug,
+	}
+
+	err = s.loadCapabilities(ctx)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	if s.mode == GNMIModeSubscribe {
+		go s.subscribe(subCtx)
+	}
+
+	return s, nil
+}
+
+// GNMISession retrieves data from the gNMI server of a device
+type GNMISession struct {
+	Host           string
+	conn           *grpc.ClientConn
+	client         pb.GNMIClient
+	username       string
+	password       string
+	encoding       pb.Encoding
+	mode           string
+	sampleInterval time.Duration
+	models         map[string]bool
+	cancel         context.CancelFunc
+	debug          bool
+
+	mu     sync.Mutex
+	cache  *gnmiCache
+	synced chan struct{}
+	// err is the error the subscription or the last get request failed with, nil if it succeeded
+	err error
+}
+
+func (s *GNMISession) rpcContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "username", s.username, "password", s.password)
+}
+
+func (s *GNMISession) loadCapabilities(ctx context.Context) error {
+	resp, err := s.client.Capabilities(s.rpcContext(ctx), &pb.CapabilityRequest{})
+	if err != nil {
+		return errors.Wrapf(err, "could not read gNMI capabilities of %s", s.Host)
+	}
+
+	for _, m := range resp.SupportedModels {
+		s.models[m.Name] = true
+	}
+
+	return nil
+}
+
+// HasModel checks if the device supports the YANG model
+func (s *GNMISession) HasModel(name string) bool {
+	return s.models[name]
+}
+
+// Models returns the names of the YANG models supported by the device
+func (s *GNMISession) Models() []string {
+	models := make([]string, 0, len(s.models))
+	for m := range s.models {
+		models = append(models, m)
+	}
+
+	return models
+}
+
+func (s *GNMISession) subscriptionPaths() []*pb.Path {
+	paths := []*pb.Path{}
+	for _, sub := range gnmiSubscriptions {
+		if s.HasModel(sub.model) {
+			paths = append(paths, parseGNMIPath(sub.path))
+		}
+	}
+
+	return paths
+}
+
+// subscribe holds a subscription until ctx is done, reconnecting after each sample interval if it fails
+func (s *GNMISession) subscribe(ctx context.Context) {
+	for {
+		err := s.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Errorf("%s: gNMI subscription failed: %v", s.Host, err)
+		s.reset(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.sampleInterval):
+		}
+	}
+}
+
+func (s *GNMISession) stream(ctx context.Context) error {
+	paths := s.subscriptionPaths()
+	if len(paths) == 0 {
+		return errors.New("device supports none of the openconfig models subscribed to")
+	}
+
+	list := &pb.SubscriptionList{
+		Mode:     pb.SubscriptionList_STREAM,
+		Encoding: s.encoding,
+	}
+	for _, p := range paths {
+		list.Subscription = append(list.Subscription, &pb.Subscription{
+			Path:           p,
+			Mode:           pb.SubscriptionMode_SAMPLE,
+			SampleInterval: uint64(s.sampleInterval.Nanoseconds()),
+		})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.client.Subscribe(s.rpcContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&pb.SubscribeRequest{Request: &pb.SubscribeRequest_Subscribe{Subscribe: list}})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		switch r := resp.Response.(type) {
+		case *pb.SubscribeResponse_Update:
+			err = s.update(r.Update)
+			if err != nil {
+				log.Errorf("%s: %v", s.Host, err)
+			}
+		case *pb.SubscribeResponse_SyncResponse:
+			if s.debug {
+				log.Infof("%s: gNMI subscription synchronized", s.Host)
+			}
+			s.markSynced()
+		}
+	}
+}
+
+func (s *GNMISession) update(n *pb.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cache.apply(n)
+}
+
+func (s *GNMISession) markSynced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.synced:
+	default:
+		close(s.synced)
+	}
+	s.err = nil
+}
+
+// reset discards the data of a failed subscription, so scrapes fail until it is reestablished
+func (s *GNMISession) reset(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache = newGNMICache()
+	s.err = err
+
+	select {
+	case <-s.synced:
+		s.synced = make(chan struct{})
+	default:
+	}
+}
+
+// failed checks if the subscription or the last get request failed
+func (s *GNMISession) failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err != nil
+}
+
+func (s *GNMISession) waitForSync(ctx context.Context) error {
+	if s.mode != GNMIModeSubscribe {
+		return nil
+	}
+
+	s.mu.Lock()
+	synced, err := s.synced, s.err
+	s.mu.Unlock()
+
+	select {
+	case <-synced:
+		return nil
+	default:
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "no gNMI subscription to %s", s.Host)
+	}
+
+	select {
+	case <-synced:
+		return nil
+	case <-ctx.Done():
+		return errors.Errorf("%s: gNMI subscription not synchronized yet", s.Host)
+	}
+}
+
+// Get returns the data at path (e.g. /interfaces) as tree of maps and lists, like in the JSON encoding of the models.
+// In subscribe mode the values received last are returned, otherwise they are retrieved using a get request.
+func (s *GNMISession) Get(ctx context.Context, path string) (map[string]interface{}, error) {
+	p := parseGNMIPath(path)
+
+	if s.mode == GNMIModeSubscribe {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return s.cache.tree(p), nil
+	}
+
+	resp, err := s.client.Get(s.rpcContext(ctx), &pb.GetRequest{
+		Path:     []*pb.Path{p},
+		Type:     pb.GetRequest_STATE,
+		Encoding: s.encoding,
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "gNMI get of %s from %s failed", path, s.Host)
+
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+
+		return nil, err
+	}
+
+	c := newGNMICache()
+	for _, n := range resp.Notification {
+		err = c.apply(n)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c.tree(p), nil
+}
+
+func (s *GNMISession) String() string {
+	return s.Host
+}
+
+// Close ends the subscription and closes the connection
+func (s *GNMISession) Close() {
+	s.cancel()
+	s.conn.Close()
+}
@@ -0,0 +1,351 @@
+package connector
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/pkg/errors"
+)
+
+// gnmiLeaf is a value received for a path. JSON encoded values can be whole subtrees.
+type gnmiLeaf struct {
+	elems []*pb.PathElem
+	value interface{}
+}
+
+// gnmiCache holds the latest values of the paths received in notifications
+type gnmiCache struct {
+	leaves map[string]gnmiLeaf
+}
+
+// gnmiList is a list of the tree built from the paths, whose entries are identified by their keys
+type gnmiList map[string]map[string]interface{}
+
+func newGNMICache() *gnmiCache {
+	return &gnmiCache{leaves: make(map[string]gnmiLeaf)}
+}
+
+// apply stores the updates and removes the deleted paths of the notification
+func (c *gnmiCache) apply(n *pb.Notification) error {
+	prefix := n.GetPrefix().GetElem()
+
+	for _, d := range n.Delete {
+		p := gnmiPathString(joinGNMIElems(prefix, d.GetElem()))
+		for k := range c.leaves {
+			if k == p || strings.HasPrefix(k, p+"/") {
+				delete(c.leaves, k)
+			}
+		}
+	}
+
+	for _, u := range n.Update {
+		elems := joinGNMIElems(prefix, u.GetPath().GetElem())
+		p := gnmiPathString(elems)
+
+		v, err := gnmiValue(u.Val)
+		if err != nil {
+			return errors.Wrapf(err, "invalid gNMI value of %s", p)
+		}
+
+		c.leaves[p] = gnmiLeaf{elems: elems, value: v}
+	}
+
+	return nil
+}
+
+// tree builds the data matching query, starting at the root of the models.
+// Lists are arrays of objects including the keys of the entries, like in the JSON encoding.
+func (c *gnmiCache) tree(query *pb.Path) map[string]interface{} {
+	paths := make([]string, 0, len(c.leaves))
+	for p, l := range c.leaves {
+		if gnmiPathMatches(query.GetElem(), l.elems) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	root := make(map[string]interface{})
+	for _, p := range paths {
+		l := c.leaves[p]
+		insertGNMIValue(root, l.elems, l.value)
+	}
+
+	return finalizeGNMITree(root).(map[string]interface{})
+}
+
+func insertGNMIValue(node map[string]interface{}, elems []*pb.PathElem, value interface{}) {
+	if len(elems) == 0 {
+		mergeGNMIValue(node, value)
+		return
+	}
+
+	for i, e := range elems {
+		last := i == len(elems)-1
+
+		if len(e.Key) > 0 {
+			list, _ := node[e.Name].(gnmiList)
+			if list == nil {
+				list = make(gnmiList)
+				node[e.Name] = list
+			}
+
+			k := gnmiKeyString(e.Key)
+			entry := list[k]
+			if entry == nil {
+				entry = make(map[string]interface{})
+				for name, v := range e.Key {
+					entry[name] = v
+				}
+				list[k] = entry
+			}
+
+			node = entry
+			if last {
+				mergeGNMIValue(node, value)
+			}
+			continue
+		}
+
+		if last {
+			if existing, ok := node[e.Name].(map[string]interface{}); ok {
+				mergeGNMIValue(existing, value)
+			} else {
+				node[e.Name] = value
+			}
+			return
+		}
+
+		child, ok := node[e.Name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			node[e.Name] = child
+		}
+		node = child
+	}
+}
+
+// mergeGNMIValue adds the members of a JSON encoded subtree to node
+func mergeGNMIValue(node map[string]interface{}, value interface{}) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for k, v := range m {
+		existing, ok := node[k].(map[string]interface{})
+		if ok {
+			mergeGNMIValue(existing, v)
+			continue
+		}
+
+		node[k] = v
+	}
+}
+
+// finalizeGNMITree converts the lists to arrays ordered by their keys
+func finalizeGNMITree(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = finalizeGNMITree(child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = finalizeGNMITree(child)
+		}
+		return v
+	case gnmiList:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		entries := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			entries = append(entries, finalizeGNMITree(v[k]))
+		}
+		return entries
+	}
+
+	return v
+}
+
+// gnmiPathMatches checks if the path is below the query or part of it. Elements of the query without keys match all list entries.
+func gnmiPathMatches(query, path []*pb.PathElem) bool {
+	for i := 0; i < len(query) && i < len(path); i++ {
+		if query[i].Name != path[i].Name {
+			return false
+		}
+
+		if len(path[i].Key) == 0 {
+			continue
+		}
+
+		for k, v := range query[i].Key {
+			if path[i].Key[k] != v {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// joinGNMIElems concatenates prefix and path, removing the module names elements might be qualified with
+func joinGNMIElems(prefix, path []*pb.PathElem) []*pb.PathElem {
+	elems := make([]*pb.PathElem, 0, len(prefix)+len(path))
+	for _, e := range append(append([]*pb.PathElem{}, prefix...), path...) {
+		elems = append(elems, &pb.PathElem{Name: stripModule(e.Name), Key: e.Key})
+	}
+
+	return elems
+}
+
+func stripModule(name string) string {
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+func gnmiKeyString(keys map[string]string) string {
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	b := &strings.Builder{}
+	for _, k := range names {
+		b.WriteString("[" + k + "=" + keys[k] + "]")
+	}
+
+	return b.String()
+}
+
+func gnmiPathString(elems []*pb.PathElem) string {
+	b := &strings.Builder{}
+	for _, e := range elems {
+		b.WriteString("/" + e.Name + gnmiKeyString(e.Key))
+	}
+
+	return b.String()
+}
+
+// parseGNMIPath parses a path like /interfaces/interface[name=Ethernet1/1]/state
+func parseGNMIPath(s string) *pb.Path {
+	p := &pb.Path{}
+
+	var elem *pb.PathElem
+	var name, key, value strings.Builder
+	inKey, inValue := false, false
+	for _, r := range strings.TrimPrefix(s, "/") {
+		switch {
+		case inValue && r == ']':
+			elem.Key[key.String()] = value.String()
+			key.Reset()
+			value.Reset()
+			inKey, inValue = false, false
+		case inValue:
+			value.WriteRune(r)
+		case inKey && r == '=':
+			inValue = true
+		case inKey:
+			key.WriteRune(r)
+		case r == '[':
+			if elem == nil {
+				elem = &pb.PathElem{Name: name.String(), Key: make(map[string]string)}
+			}
+			inKey = true
+		case r == '/':
+			if elem == nil {
+				elem = &pb.PathElem{Name: name.String()}
+			}
+			p.Elem = append(p.Elem, elem)
+			elem = nil
+			name.Reset()
+		default:
+			name.WriteRune(r)
+		}
+	}
+
+	if elem == nil && name.Len() > 0 {
+		elem = &pb.PathElem{Name: name.String()}
+	}
+	if elem != nil {
+		p.Elem = append(p.Elem, elem)
+	}
+
+	return p
+}
+
+// gnmiValue converts a typed value to the types used by encoding/json
+func gnmiValue(v *pb.TypedValue) (interface{}, error) {
+	switch v := v.GetValue().(type) {
+	case *pb.TypedValue_StringVal:
+		return v.StringVal, nil
+	case *pb.TypedValue_IntVal:
+		return v.IntVal, nil
+	case *pb.TypedValue_UintVal:
+		return v.UintVal, nil
+	case *pb.TypedValue_BoolVal:
+		return v.BoolVal, nil
+	case *pb.TypedValue_FloatVal:
+		return float64(v.FloatVal), nil
+	case *pb.TypedValue_DoubleVal:
+		return v.DoubleVal, nil
+	case *pb.TypedValue_DecimalVal:
+		return float64(v.DecimalVal.Digits) / math.Pow10(int(v.DecimalVal.Precision)), nil
+	case *pb.TypedValue_AsciiVal:
+		return v.AsciiVal, nil
+	case *pb.TypedValue_BytesVal:
+		return v.BytesVal, nil
+	case *pb.TypedValue_JsonVal:
+		return decodeGNMIJSON(v.JsonVal)
+	case *pb.TypedValue_JsonIetfVal:
+		return decodeGNMIJSON(v.JsonIetfVal)
+	case *pb.TypedValue_LeaflistVal:
+		l := make([]interface{}, 0, len(v.LeaflistVal.Element))
+		for _, e := range v.LeaflistVal.Element {
+			ev, err := gnmiValue(e)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, ev)
+		}
+		return l, nil
+	}
+
+	return nil, errors.Errorf("unsupported value type %T", v.GetValue())
+}
+
+func decodeGNMIJSON(b []byte) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return stripJSONModules(v), nil
+}
+
+// stripJSONModules removes the module names members of JSON_IETF encoded values are qualified with
+func stripJSONModules(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			m[stripModule(k)] = stripJSONModules(child)
+		}
+		return m
+	case []interface{}:
+		for i, child := range v {
+			v[i] = stripJSONModules(child)
+		}
+		return v
+	}
+
+	return v
+}
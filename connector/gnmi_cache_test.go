@@ -0,0 +1,278 @@
+package connector
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func stringVal(s string) *pb.TypedValue {
+	return &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: s}}
+}
+
+func jsonIetfVal(s string) *pb.TypedValue {
+	return &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(s)}}
+}
+
+func gnmiUpdate(path string, val *pb.TypedValue) *pb.Update {
+	return &pb.Update{Path: parseGNMIPath(path), Val: val}
+}
+
+func TestGNMICacheTree(t *testing.T) {
+	tests := []struct {
+		name          string
+		notifications []*pb.Notification
+		query         string
+		want          map[string]interface{}
+	}{
+		{
+			name: "updates below a module qualified prefix",
+			notifications: []*pb.Notification{{
+				Prefix: parseGNMIPath("/openconfig-interfaces:interfaces"),
+				Update: []*pb.Update{
+					gnmiUpdate("/interface[name=Eth2]/state/oper-status", stringVal("DOWN")),
+					gnmiUpdate("/interface[name=Eth1]/state/oper-status", stringVal("UP")),
+				},
+			}},
+			query: "/interfaces",
+			want: map[string]interface{}{
+				"interfaces": map[string]interface{}{
+					"interface": []interface{}{
+						map[string]interface{}{"name": "Eth1", "state": map[string]interface{}{"oper-status": "UP"}},
+						map[string]interface{}{"name": "Eth2", "state": map[string]interface{}{"oper-status": "DOWN"}},
+					},
+				},
+			},
+		},
+		{
+			name: "later updates replace earlier values",
+			notifications: []*pb.Notification{
+				{Update: []*pb.Update{gnmiUpdate("/interfaces/interface[name=Eth1]/state/oper-status", stringVal("UP"))}},
+				{Update: []*pb.Update{gnmiUpdate("/interfaces/interface[name=Eth1]/state/oper-status", stringVal("DOWN"))}},
+			},
+			query: "/interfaces",
+			want: map[string]interface{}{
+				"interfaces": map[string]interface{}{
+					"interface": []interface{}{
+						map[string]interface{}{"name": "Eth1", "state": map[string]interface{}{"oper-status": "DOWN"}},
+					},
+				},
+			},
+		},
+		{
+			name: "delete removes only the subtree of the list entry",
+			notifications: []*pb.Notification{
+				{Update: []*pb.Update{
+					gnmiUpdate("/interfaces/interface[name=Eth1]/state/oper-status", stringVal("UP")),
+					gnmiUpdate("/interfaces/interface[name=Eth1]/state/description", stringVal("uplink")),
+					gnmiUpdate("/interfaces/interface[name=Eth10]/state/oper-status", stringVal("UP")),
+				}},
+				{Delete: []*pb.Path{parseGNMIPath("/interfaces/interface[name=Eth1]")}},
+			},
+			query: "/interfaces",
+			want: map[string]interface{}{
+				"interfaces": map[string]interface{}{
+					"interface": []interface{}{
+						map[string]interface{}{"name": "Eth10", "state": map[string]interface{}{"oper-status": "UP"}},
+					},
+				},
+			},
+		},
+		{
+			name: "delete below prefix",
+			notifications: []*pb.Notification{
+				{Update: []*pb.Update{
+					gnmiUpdate("/interfaces/interface[name=Eth1]/state/oper-status", stringVal("UP")),
+					gnmiUpdate("/interfaces/interface[name=Eth1]/state/description", stringVal("uplink")),
+				}},
+				{
+					Prefix: parseGNMIPath("/interfaces/interface[name=Eth1]"),
+					Delete: []*pb.Path{parseGNMIPath("/state/description")},
+				},
+			},
+			query: "/interfaces",
+			want: map[string]interface{}{
+				"interfaces": map[string]interface{}{
+					"interface": []interface{}{
+						map[string]interface{}{"name": "Eth1", "state": map[string]interface{}{"oper-status": "UP"}},
+					},
+				},
+			},
+		},
+		{
+			name: "query with key",
+			notifications: []*pb.Notification{{Update: []*pb.Update{
+				gnmiUpdate("/interfaces/interface[name=Eth1]/state/oper-status", stringVal("UP")),
+				gnmiUpdate("/interfaces/interface[name=Eth2]/state/oper-status", stringVal("DOWN")),
+				gnmiUpdate("/components/component[name=PSU1]/state/type", stringVal("POWER_SUPPLY")),
+			}}},
+			query: "/interfaces/interface[name=Eth2]",
+			want: map[string]interface{}{
+				"interfaces": map[string]interface{}{
+					"interface": []interface{}{
+						map[string]interface{}{"name": "Eth2", "state": map[string]interface{}{"oper-status": "DOWN"}},
+					},
+				},
+			},
+		},
+		{
+			name: "JSON subtrees are merged with leaves",
+			notifications: []*pb.Notification{{Update: []*pb.Update{
+				gnmiUpdate("/interfaces/interface[name=Eth1]/state/counters/out-octets", &pb.TypedValue{Value: &pb.TypedValue_UintVal{UintVal: 7}}),
+				gnmiUpdate("/interfaces/interface[name=Eth1]/state", jsonIetfVal(`{"openconfig-interfaces:oper-status": "UP", "counters": {"in-octets": "5"}}`)),
+			}}},
+			query: "/interfaces",
+			want: map[string]interface{}{
+				"interfaces": map[string]interface{}{
+					"interface": []interface{}{
+						map[string]interface{}{
+							"name": "Eth1",
+							"state": map[string]interface{}{
+								"oper-status": "UP",
+								"counters":    map[string]interface{}{"in-octets": "5", "out-octets": uint64(7)},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "list entries with several keys",
+			notifications: []*pb.Notification{{Update: []*pb.Update{
+				gnmiUpdate("/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=bgp]/bgp/global/state/as", &pb.TypedValue{Value: &pb.TypedValue_UintVal{UintVal: 65000}}),
+			}}},
+			query: "/network-instances",
+			want: map[string]interface{}{
+				"network-instances": map[string]interface{}{
+					"network-instance": []interface{}{
+						map[string]interface{}{
+							"name": "default",
+							"protocols": map[string]interface{}{
+								"protocol": []interface{}{
+									map[string]interface{}{
+										"identifier": "BGP",
+										"name":       "bgp",
+										"bgp":        map[string]interface{}{"global": map[string]interface{}{"state": map[string]interface{}{"as": uint64(65000)}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "empty cache",
+			query: "/interfaces",
+			want:  map[string]interface{}{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newGNMICache()
+			for _, n := range test.notifications {
+				if err := c.apply(n); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got := c.tree(parseGNMIPath(test.query))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGNMICacheApplyInvalidValue(t *testing.T) {
+	c := newGNMICache()
+	err := c.apply(&pb.Notification{Update: []*pb.Update{gnmiUpdate("/interfaces", jsonIetfVal(`{"interface":`))}})
+	if err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestGNMIPathMatches(t *testing.T) {
+	tests := []struct {
+		query string
+		path  string
+		want  bool
+	}{
+		{query: "/interfaces", path: "/interfaces/interface[name=Eth1]/state", want: true},
+		{query: "/interfaces/interface", path: "/interfaces/interface[name=Eth1]/state", want: true},
+		{query: "/interfaces/interface[name=Eth1]", path: "/interfaces/interface[name=Eth1]/state", want: true},
+		{query: "/interfaces/interface[name=Eth1]", path: "/interfaces/interface[name=Eth2]/state", want: false},
+		{query: "/interfaces/interface[name=Eth1]", path: "/interfaces/interface[name=Eth10]/state", want: false},
+		{query: "/components", path: "/interfaces/interface[name=Eth1]/state", want: false},
+		// values of paths above the query are JSON subtrees containing it
+		{query: "/interfaces/interface[name=Eth1]/state", path: "/interfaces", want: true},
+		{query: "/interfaces/interface[name=Eth1]", path: "/interfaces/interface", want: true},
+		{query: "/", path: "/interfaces", want: true},
+	}
+
+	for _, test := range tests {
+		got := gnmiPathMatches(parseGNMIPath(test.query).Elem, parseGNMIPath(test.path).Elem)
+		if got != test.want {
+			t.Errorf("gnmiPathMatches(%s, %s) = %v, want %v", test.query, test.path, got, test.want)
+		}
+	}
+}
+
+func TestFinalizeGNMITree(t *testing.T) {
+	tree := map[string]interface{}{
+		"interface": gnmiList{
+			"[name=b]": map[string]interface{}{"name": "b"},
+			"[name=a]": map[string]interface{}{
+				"name": "a",
+				"subinterface": gnmiList{
+					"[index=1]": map[string]interface{}{"index": "1"},
+					"[index=0]": map[string]interface{}{"index": "0"},
+				},
+			},
+		},
+		"leaf-list": []interface{}{map[string]interface{}{"x": gnmiList{"[k=v]": map[string]interface{}{"k": "v"}}}},
+		"leaf":      "value",
+	}
+
+	want := map[string]interface{}{
+		"interface": []interface{}{
+			map[string]interface{}{
+				"name": "a",
+				"subinterface": []interface{}{
+					map[string]interface{}{"index": "0"},
+					map[string]interface{}{"index": "1"},
+				},
+			},
+			map[string]interface{}{"name": "b"},
+		},
+		"leaf-list": []interface{}{map[string]interface{}{"x": []interface{}{map[string]interface{}{"k": "v"}}}},
+		"leaf":      "value",
+	}
+
+	got := finalizeGNMITree(tree)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestParseGNMIPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/interfaces/interface/state", want: "/interfaces/interface/state"},
+		{path: "interfaces", want: "/interfaces"},
+		{path: "/interfaces/interface[name=Ethernet1/1]/state", want: "/interfaces/interface[name=Ethernet1/1]/state"},
+		{path: "/protocols/protocol[name=bgp][identifier=BGP]", want: "/protocols/protocol[identifier=BGP][name=bgp]"},
+		{path: "/", want: ""},
+	}
+
+	for _, test := range tests {
+		got := gnmiPathString(parseGNMIPath(test.path).Elem)
+		if got != test.want {
+			t.Errorf("parseGNMIPath(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
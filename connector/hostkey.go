@@ -0,0 +1,86 @@
+package connector
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var knownHostsMu sync.Mutex
+
+// HostKeyMismatchError is returned when a device presents a host key different from the one in known_hosts
+type HostKeyMismatchError struct {
+	Host string
+	Key  ssh.PublicKey
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("host key mismatch for %s: got %s %s", e.Host, e.Key.Type(), ssh.FingerprintSHA256(e.Key))
+}
+
+// hostKeyCallback verifies host keys against knownHostsFile. If trustOnFirstUse is set
+// keys of hosts not yet present in the file are accepted and appended to it.
+// Host keys are only accepted without verification if insecureSkipVerify is set.
+func hostKeyCallback(knownHostsFile string, trustOnFirstUse bool, insecureSkipVerify bool) (ssh.HostKeyCallback, error) {
+	if insecureSkipVerify {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	if knownHostsFile == "" {
+		if trustOnFirstUse {
+			return nil, errors.New("trust on first use requires a known hosts file")
+		}
+
+		return nil, errors.New("host key verification requires a known hosts file (or insecure_skip_host_key_verify to skip it)")
+	}
+
+	if trustOnFirstUse {
+		f, err := os.OpenFile(knownHostsFile, os.O_CREATE|os.O_RDONLY, 0600)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create known hosts file")
+		}
+		f.Close()
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		knownHostsMu.Lock()
+		defer knownHostsMu.Unlock()
+
+		cb, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return errors.Wrap(err, "could not load known hosts file")
+		}
+
+		err = cb(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return err
+		}
+
+		if len(keyErr.Want) > 0 {
+			return &HostKeyMismatchError{Host: hostname, Key: key}
+		}
+
+		if !trustOnFirstUse {
+			return errors.Errorf("host key for %s not found in %s", hostname, knownHostsFile)
+		}
+
+		return appendKnownHost(knownHostsFile, hostname, key)
+	}, nil
+}
+
+func appendKnownHost(knownHostsFile, hostname string, key ssh.PublicKey) error {
+	f, err := os.OpenFile(knownHostsFile, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not open known hosts file")
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+	return err
+}
@@ -0,0 +1,70 @@
+package connector
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
+)
+
+// newAPIClient creates a client for the HTTP based API of host configured by ac and returns the base URL of the API.
+// Without port configured the default port of the scheme is used.
+func newAPIClient(host string, ac *config.APIConfig, timeout time.Duration) (*http.Client, string, error) {
+	if ac == nil {
+		ac = &config.APIConfig{}
+	}
+
+	scheme := ac.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	if scheme != "https" && scheme != "http" {
+		return nil, "", errors.Errorf("unknown scheme %q", scheme)
+	}
+
+	port := ac.Port
+	if port == 0 && scheme == "http" {
+		port = 80
+	} else if port == 0 {
+		port = 443
+	}
+
+	tlsConfig, err := newTLSConfig(ac.CAFile, ac.InsecureSkipVerify)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+
+	return client, fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(port))), nil
+}
+
+// newTLSConfig creates a client TLS config verifying certificates against the CA in caFile, or the system CAs if empty
+func newTLSConfig(caFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	if caFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read CA file")
+	}
+
+	tlsConfig.RootCAs = x509.NewCertPool()
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in %s", caFile)
+	}
+
+	return tlsConfig, nil
+}
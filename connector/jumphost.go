@@ -0,0 +1,138 @@
+package connector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// JumpHost is a bastion host a device is reached through
+type JumpHost struct {
+	Host     string
+	Port     string
+	Username string
+	Auth     []AuthMethod
+
+	// JumpHost is the bastion this jump host is reached through, if any
+	JumpHost *JumpHost
+}
+
+func (j *JumpHost) String() string {
+	s := j.Username + "@" + j.Host + ":" + j.Port
+	if j.JumpHost != nil {
+		s += " via " + j.JumpHost.String()
+	}
+
+	return s
+}
+
+type jumpConnection struct {
+	key  string
+	refs int // guarded by jumpConnectionsMu
+
+	// setup is held while the connection to the jump host is checked or established,
+	// so only devices using this jump host wait for it
+	setup  sync.Mutex
+	client *ssh.Client     // guarded by setup
+	parent *jumpConnection // guarded by setup
+}
+
+var (
+	jumpConnectionsMu sync.Mutex
+	jumpConnections   = make(map[string]*jumpConnection)
+)
+
+// acquireJumpConnection returns an SSH connection to the jump host, shared with all devices using the same one,
+// and the client to tunnel through. baseConfig provides the settings besides authentication used to connect to the jump host.
+func acquireJumpConnection(ctx context.Context, j *JumpHost, baseConfig *ssh.ClientConfig) (*jumpConnection, *ssh.Client, error) {
+	key := j.String()
+
+	jumpConnectionsMu.Lock()
+	jc, found := jumpConnections[key]
+	if !found {
+		jc = &jumpConnection{key: key}
+		jumpConnections[key] = jc
+	}
+	jc.refs++
+	jumpConnectionsMu.Unlock()
+
+	client, err := jc.connect(ctx, j, baseConfig)
+	if err != nil {
+		releaseJumpConnection(jc)
+		return nil, nil, err
+	}
+
+	return jc, client, nil
+}
+
+// connect returns the client connected to the jump host, reconnecting if the current one is not alive anymore
+func (jc *jumpConnection) connect(ctx context.Context, j *JumpHost, baseConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	jc.setup.Lock()
+	defer jc.setup.Unlock()
+
+	if jc.client != nil && isAlive(jc.client, baseConfig.Timeout) {
+		return jc.client, nil
+	}
+
+	var parent *jumpConnection
+	var via *ssh.Client
+	if j.JumpHost != nil {
+		var err error
+		parent, via, err = acquireJumpConnection(ctx, j.JumpHost, baseConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &ssh.ClientConfig{
+		Config:          baseConfig.Config,
+		HostKeyCallback: baseConfig.HostKeyCallback,
+		Timeout:         baseConfig.Timeout,
+	}
+
+	client, err := dialAuth(ctx, via, j.Host+":"+j.Port, cfg, j.Auth)
+	if err != nil {
+		if parent != nil {
+			releaseJumpConnection(parent)
+		}
+		return nil, errors.Wrapf(err, "could not connect to jump host %s", j.Host)
+	}
+
+	if jc.client != nil {
+		// the previous connection dropped, users of it will fail and release it
+		jc.client.Close()
+		if jc.parent != nil {
+			releaseJumpConnection(jc.parent)
+		}
+	}
+	jc.client = client
+	jc.parent = parent
+
+	return client, nil
+}
+
+// releaseJumpConnection closes the connection to the jump host once no device uses it anymore
+func releaseJumpConnection(jc *jumpConnection) {
+	jumpConnectionsMu.Lock()
+	jc.refs--
+	if jc.refs > 0 {
+		jumpConnectionsMu.Unlock()
+		return
+	}
+	delete(jumpConnections, jc.key)
+	jumpConnectionsMu.Unlock()
+
+	jc.setup.Lock()
+	client, parent := jc.client, jc.parent
+	jc.client, jc.parent = nil, nil
+	jc.setup.Unlock()
+
+	if client != nil {
+		client.Close()
+	}
+	if parent != nil {
+		releaseJumpConnection(parent)
+	}
+}
@@ -0,0 +1,214 @@
+package connector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+)
+
+// ConnectionManager keeps one authenticated connection per device and module alive between scrapes
+type ConnectionManager struct {
+	idleTimeout time.Duration
+	mu          sync.Mutex
+	connections map[string]*managedConnection
+	done        chan struct{}
+}
+
+type managedConnection struct {
+	host       string
+	inUse      chan struct{} // holds one element while the connection is reserved by a scrape
+	conn       Connection
+	lastUsed   time.Time
+	reconnects uint64 // guarded by ConnectionManager.mu
+}
+
+// ManagerStats describes the state of the connections held by a ConnectionManager
+type ManagerStats struct {
+	// PoolSize is the number of devices (per module) the manager keeps a connection for
+	PoolSize int
+
+	// LiveSessions is the number of currently established connections
+	LiveSessions int
+
+	// Reconnects is the number of reconnects per device after a session dropped
+	Reconnects map[string]uint64
+}
+
+// NewConnectionManager creates a connection manager closing connections unused for idleTimeout
+func NewConnectionManager(idleTimeout time.Duration) *ConnectionManager {
+	m := &ConnectionManager{
+		idleTimeout: idleTimeout,
+		connections: make(map[string]*managedConnection),
+		done:        make(chan struct{}),
+	}
+
+	go m.closeIdleConnections()
+
+	return m
+}
+
+// Connection returns a connected session for device, reconnecting if the last one is not usable anymore.
+// On success the connection is reserved for the caller until Release is called.
+func (m *ConnectionManager) Connection(ctx context.Context, device *Device, cfg *config.Config) (Connection, error) {
+	mc := m.managedConnection(device)
+
+	select {
+	case mc.inUse <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if mc.conn != nil && mc.conn.Alive() {
+		return mc.conn, nil
+	}
+
+	if mc.conn != nil {
+		mc.conn.Close()
+		mc.conn = nil
+
+		m.mu.Lock()
+		mc.reconnects++
+		m.mu.Unlock()
+	}
+
+	conn, err := NewConnection(ctx, device, cfg)
+	if err != nil {
+		mc.lastUsed = time.Now()
+		mc.release()
+		return nil, err
+	}
+	mc.conn = conn
+
+	return conn, nil
+}
+
+// Release hands the connection of device back to the manager
+func (m *ConnectionManager) Release(device *Device) {
+	mc := m.managedConnection(device)
+	mc.lastUsed = time.Now()
+	mc.release()
+}
+
+// Stats returns the current state of the managed connections
+func (m *ConnectionManager) Stats() ManagerStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := ManagerStats{
+		PoolSize:   len(m.connections),
+		Reconnects: make(map[string]uint64),
+	}
+
+	for _, mc := range m.connections {
+		s.Reconnects[mc.host] += mc.reconnects
+
+		if !mc.tryReserve() {
+			// connection is in use by a scrape
+			s.LiveSessions++
+			continue
+		}
+
+		if mc.conn != nil {
+			s.LiveSessions++
+		}
+		mc.release()
+	}
+
+	return s
+}
+
+// Close closes all managed connections, waiting for scrapes still using one to release it
+func (m *ConnectionManager) Close() {
+	close(m.done)
+
+	// scrapes release their connection through managedConnection, so m.mu must not be held while waiting for them
+	m.mu.Lock()
+	connections := make([]*managedConnection, 0, len(m.connections))
+	for _, mc := range m.connections {
+		connections = append(connections, mc)
+	}
+	m.mu.Unlock()
+
+	for _, mc := range connections {
+		mc.inUse <- struct{}{}
+		if mc.conn != nil {
+			mc.conn.Close()
+			mc.conn = nil
+		}
+		mc.release()
+	}
+}
+
+func (m *ConnectionManager) managedConnection(device *Device) *managedConnection {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := poolKey(device)
+	mc, found := m.connections[key]
+	if !found {
+		mc = &managedConnection{
+			host:  device.Host,
+			inUse: make(chan struct{}, 1),
+		}
+		m.connections[key] = mc
+	}
+
+	return mc
+}
+
+// poolKey returns the key the connection of device is kept under. Requests selecting a module get a connection of
+// their own, so the settings of the module (e.g. the timeout) are used when it is opened.
+func poolKey(device *Device) string {
+	if device.Module == "" {
+		return device.Host
+	}
+
+	return device.Host + "/" + device.Module
+}
+
+func (m *ConnectionManager) closeIdleConnections() {
+	interval := m.idleTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-t.C:
+		}
+
+		m.mu.Lock()
+		for _, mc := range m.connections {
+			if !mc.tryReserve() {
+				continue
+			}
+
+			if mc.conn != nil && time.Since(mc.lastUsed) > m.idleTimeout {
+				mc.conn.Close()
+				mc.conn = nil
+			}
+			mc.release()
+		}
+		m.mu.Unlock()
+	}
+}
+
+func (mc *managedConnection) tryReserve() bool {
+	select {
+	case mc.inUse <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (mc *managedConnection) release() {
+	<-mc.inUse
+}
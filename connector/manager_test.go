@@ -0,0 +1,32 @@
+package connector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConnectionManagerPoolsPerModule(t *testing.T) {
+	m := NewConnectionManager(time.Minute)
+	defer m.Close()
+
+	plain := &Device{Host: "r1"}
+	core := &Device{Host: "r1", Module: "core"}
+
+	if m.managedConnection(plain) == m.managedConnection(core) {
+		t.Fatal("device with module shares the connection of the device without")
+	}
+	if m.managedConnection(core) != m.managedConnection(&Device{Host: "r1", Module: "core"}) {
+		t.Fatal("device with the same module got a new connection")
+	}
+
+	m.managedConnection(plain).reconnects = 1
+	m.managedConnection(core).reconnects = 2
+
+	s := m.Stats()
+	if s.PoolSize != 2 {
+		t.Errorf("got pool size %d, want 2", s.PoolSize)
+	}
+	if s.Reconnects["r1"] != 3 {
+		t.Errorf("got %d reconnects, want 3", s.Reconnects["r1"])
+	}
+}
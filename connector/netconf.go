@@ -0,0 +1,299 @@
+package connector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	netconfBase10      = "urn:ietf:params:netconf:base:1.0"
+	netconfBase11      = "urn:ietf:params:netconf:base:1.1"
+	netconfEndOfMsg    = "]]>]]>"
+	netconfClientHello = `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<hello xmlns="` + netconfBase10 + `"><capabilities>` +
+		`<capability>` + netconfBase10 + `</capability>` +
+		`<capability>` + netconfBase11 + `</capability>` +
+		`</capabilities></hello>`
+)
+
+// ErrNetconfNotSupported is returned when a device refuses to start the NETCONF subsystem
+var ErrNetconfNotSupported = errors.New("netconf subsystem not supported")
+
+// NewNetconfSession opens a NETCONF session to device using the SSH subsystem "netconf"
+func NewNetconfSession(ctx context.Context, device *Device, cfg *config.Config) (*NetconfSession, error) {
+	timeout := cfg.Timeout
+	if device.DeviceConfig.Timeout != nil {
+		timeout = *device.DeviceConfig.Timeout
+	}
+
+	s := &NetconfSession{
+		Host:    device.Host + ":" + device.NetconfPort,
+		timeout: time.Duration(timeout) * time.Second,
+	}
+
+	sshConfig, err := sshClientConfig(device, cfg, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	s.client, s.jump, err = connectSSH(ctx, s.Host, sshConfig, device.JumpHost, device.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.start(ctx)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// NetconfSession is a NETCONF session to a device
+type NetconfSession struct {
+	Host         string
+	client       *ssh.Client
+	session      *ssh.Session
+	jump         *jumpConnection
+	stdin        io.Writer
+	stdout       *bufio.Reader
+	timeout      time.Duration
+	chunked      bool
+	capabilities []string
+	messageID    int
+	mu           sync.Mutex
+}
+
+type netconfHello struct {
+	Capabilities []string `xml:"capabilities>capability"`
+}
+
+type netconfReply struct {
+	Data struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"data"`
+	Errors []netconfError `xml:"rpc-error"`
+}
+
+type netconfError struct {
+	Type     string `xml:"error-type"`
+	Tag      string `xml:"error-tag"`
+	Severity string `xml:"error-severity"`
+	Message  string `xml:"error-message"`
+}
+
+func (e netconfError) Error() string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s: %s", e.Type, e.Tag, e.Message))
+}
+
+func (s *NetconfSession) start(ctx context.Context) error {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return err
+	}
+	s.session = session
+
+	s.stdin, _ = session.StdinPipe()
+	stdout, _ := session.StdoutPipe()
+	s.stdout = bufio.NewReader(stdout)
+
+	err = session.RequestSubsystem("netconf")
+	if err != nil {
+		return errors.Wrapf(ErrNetconfNotSupported, "could not start netconf subsystem: %v", err)
+	}
+
+	msg, err := s.exchange(ctx, netconfClientHello)
+	if err != nil {
+		return errors.Wrap(err, "no netconf hello received")
+	}
+
+	hello := &netconfHello{}
+	err = xml.Unmarshal(msg, hello)
+	if err != nil {
+		return errors.Wrap(err, "could not parse netconf hello")
+	}
+
+	for _, c := range hello.Capabilities {
+		s.capabilities = append(s.capabilities, strings.TrimSpace(c))
+	}
+
+	// the framing changes to chunks after the hello if both sides support base:1.1
+	s.chunked = s.HasCapability(netconfBase11)
+
+	return nil
+}
+
+// Capabilities returns the capabilities announced by the device
+func (s *NetconfSession) Capabilities() []string {
+	return s.capabilities
+}
+
+// HasCapability checks if the device announced a capability starting with prefix
+func (s *NetconfSession) HasCapability(prefix string) bool {
+	for _, c := range s.capabilities {
+		if strings.HasPrefix(c, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get retrieves the state data matching the subtree filter and returns the content of the data element
+func (s *NetconfSession) Get(ctx context.Context, filter string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messageID++
+	rpc := fmt.Sprintf(`<rpc message-id="%d" xmlns="%s"><get><filter type="subtree">%s</filter></get></rpc>`, s.messageID, netconfBase10, filter)
+
+	msg, err := s.exchange(ctx, rpc)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &netconfReply{}
+	err = xml.Unmarshal(msg, reply)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse netconf reply")
+	}
+
+	for _, e := range reply.Errors {
+		if e.Severity != "warning" {
+			return nil, e
+		}
+	}
+
+	return reply.Data.Inner, nil
+}
+
+// exchange sends a message and waits for the next message of the device
+func (s *NetconfSession) exchange(ctx context.Context, msg string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		msg []byte
+		err error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		err := s.write(msg)
+		if err != nil {
+			ch <- result{err: err}
+			return
+		}
+
+		m, err := s.read()
+		ch <- result{msg: m, err: err}
+	}()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-ch:
+		return res.msg, res.err
+	case <-timer.C:
+		s.client.Close()
+		return nil, errors.New("Timeout reached")
+	case <-ctx.Done():
+		s.client.Close()
+		return nil, ctx.Err()
+	}
+}
+
+func (s *NetconfSession) write(msg string) error {
+	if s.chunked {
+		_, err := fmt.Fprintf(s.stdin, "\n#%d\n%s\n##\n", len(msg), msg)
+		return err
+	}
+
+	_, err := io.WriteString(s.stdin, msg+netconfEndOfMsg)
+	return err
+}
+
+func (s *NetconfSession) read() ([]byte, error) {
+	if s.chunked {
+		return s.readChunked()
+	}
+
+	var msg []byte
+	for {
+		b, err := s.stdout.ReadBytes('>')
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, b...)
+
+		if bytes.HasSuffix(msg, []byte(netconfEndOfMsg)) {
+			return msg[:len(msg)-len(netconfEndOfMsg)], nil
+		}
+	}
+}
+
+// readChunked reads a message in the chunked framing of RFC 6242
+func (s *NetconfSession) readChunked() ([]byte, error) {
+	var msg []byte
+	for {
+		header, err := s.stdout.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+
+		header = strings.TrimSpace(header)
+		if header == "##" {
+			return msg, nil
+		}
+		if !strings.HasPrefix(header, "#") {
+			return nil, errors.Errorf("invalid netconf chunk header %q", header)
+		}
+
+		size, err := strconv.Atoi(header[1:])
+		if err != nil {
+			return nil, errors.Errorf("invalid netconf chunk header %q", header)
+		}
+
+		chunk := make([]byte, size)
+		_, err = io.ReadFull(s.stdout, chunk)
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, chunk...)
+	}
+}
+
+func (s *NetconfSession) String() string {
+	return s.Host
+}
+
+// Close closes the session
+func (s *NetconfSession) Close() {
+	if s.session != nil {
+		s.session.Close()
+	}
+	s.client.Close()
+
+	if s.jump != nil {
+		releaseJumpConnection(s.jump)
+		s.jump = nil
+	}
+}
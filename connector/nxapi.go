@@ -0,0 +1,187 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	nxapiShow      = "cli_show"
+	nxapiShowASCII = "cli_show_ascii"
+)
+
+// NewNXAPIConnection creates a connection to the NX-API of device. Commands are sent as HTTP requests,
+// there is no session kept open between them. Access to the API is verified by requesting the version.
+func NewNXAPIConnection(ctx context.Context, device *Device, cfg *config.Config) (*NXAPIConnection, error) {
+	timeout := cfg.Timeout
+	if device.DeviceConfig.Timeout != nil {
+		timeout = *device.DeviceConfig.Timeout
+	}
+
+	client, baseURL, err := newAPIClient(device.Host, device.NXAPI, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not initialize NX-API client")
+	}
+
+	c := &NXAPIConnection{
+		Host:     device.Host,
+		url:      baseURL + "/ins",
+		username: device.Username,
+		password: device.Password,
+		client:   client,
+	}
+
+	_, err = c.Show(ctx, "show version")
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// NXAPIConnection runs commands using the NX-API of Nexus switches
+type NXAPIConnection struct {
+	Host     string
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+type nxapiRequest struct {
+	InsAPI nxapiRequestBody `json:"ins_api"`
+}
+
+type nxapiRequestBody struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Chunk        string `json:"chunk"`
+	SID          string `json:"sid"`
+	Input        string `json:"input"`
+	OutputFormat string `json:"output_format"`
+}
+
+type nxapiResponse struct {
+	InsAPI struct {
+		Outputs struct {
+			Output nxapiOutput `json:"output"`
+		} `json:"outputs"`
+	} `json:"ins_api"`
+}
+
+type nxapiOutput struct {
+	Code           string          `json:"code"`
+	Msg            string          `json:"msg"`
+	ClientErrorMsg string          `json:"clierror"`
+	Body           json.RawMessage `json:"body"`
+}
+
+// Show runs a show command and returns its structured output as JSON
+func (c *NXAPIConnection) Show(ctx context.Context, cmd string) (json.RawMessage, error) {
+	return c.request(ctx, nxapiShow, cmd)
+}
+
+// RunCommand runs a command and returns its output as shown on the CLI
+func (c *NXAPIConnection) RunCommand(ctx context.Context, cmd string) (string, error) {
+	body, err := c.request(ctx, nxapiShowASCII, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	var out string
+	err = json.Unmarshal(body, &out)
+	if err != nil {
+		return "", errors.Wrapf(err, "unexpected NX-API output for %q", cmd)
+	}
+
+	return out, nil
+}
+
+// RunCommandExpect is not supported by NX-API, there is no prompt to wait for
+func (c *NXAPIConnection) RunCommandExpect(ctx context.Context, cmd string, prompt *regexp.Regexp) (string, error) {
+	return "", errors.Errorf("running interactive command %q is not supported using NX-API", cmd)
+}
+
+func (c *NXAPIConnection) request(ctx context.Context, typ, cmd string) (json.RawMessage, error) {
+	b, err := json.Marshal(&nxapiRequest{
+		InsAPI: nxapiRequestBody{
+			Version:      "1.0",
+			Type:         typ,
+			Chunk:        "0",
+			SID:          "1",
+			Input:        cmd,
+			OutputFormat: "json",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "NX-API request to %s failed", c.Host)
+	}
+	defer resp.Body.Close()
+
+	b, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read NX-API response of %s", c.Host)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errors.Errorf("NX-API authentication failed for %s", c.Host)
+	}
+
+	r := &nxapiResponse{}
+	err = json.Unmarshal(b, r)
+	if err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("NX-API request to %s failed: %s", c.Host, resp.Status)
+		}
+
+		return nil, errors.Wrapf(err, "could not parse NX-API response of %s", c.Host)
+	}
+
+	out := r.InsAPI.Outputs.Output
+	if out.Code != "200" {
+		msg := out.Msg
+		if out.ClientErrorMsg != "" {
+			msg += ": " + strings.TrimSpace(out.ClientErrorMsg)
+		}
+
+		return nil, errors.Errorf("%q failed on %s: %s (%s)", cmd, c.Host, msg, out.Code)
+	}
+
+	return out.Body, nil
+}
+
+// Alive checks if the connection can still be used to run commands
+func (c *NXAPIConnection) Alive() bool {
+	return true
+}
+
+func (c *NXAPIConnection) String() string {
+	return c.Host
+}
+
+// Close closes idle HTTP connections to the device
+func (c *NXAPIConnection) Close() {
+	c.client.CloseIdleConnections()
+}
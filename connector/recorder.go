@@ -0,0 +1,136 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	transcriptCommandPrefix = "### command: "
+	transcriptErrorPrefix   = "### error: "
+	transcriptEnd           = "### end"
+	transcriptSuffix        = ".txt"
+)
+
+var unsafeFileNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Recorder writes each command run on a device and its raw output to a transcript file.
+// Every recorded session gets a new file in a directory per device, only the newest maxFiles are kept.
+type Recorder struct {
+	dir      string
+	maxFiles int
+	mu       sync.Mutex
+}
+
+// NewRecorder creates a recorder writing transcripts to dir
+func NewRecorder(dir string, maxFiles int) (*Recorder, error) {
+	err := os.MkdirAll(dir, 0750)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create transcript directory")
+	}
+
+	return &Recorder{dir: dir, maxFiles: maxFiles}, nil
+}
+
+// Record returns a connection writing a transcript of all commands run through it.
+// The returned closer ends the transcript, it does not close conn.
+func (r *Recorder) Record(conn Connection, host string) (Connection, io.Closer, error) {
+	dir := filepath.Join(r.dir, fileNameForHost(host))
+	err := os.MkdirAll(dir, 0750)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not create transcript directory")
+	}
+
+	name := time.Now().UTC().Format("20060102T150405.000000000Z") + transcriptSuffix
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not create transcript file")
+	}
+
+	fmt.Fprintf(f, "# host: %s\n# started: %s\n", host, time.Now().Format(time.RFC3339))
+
+	err = r.removeOldTranscripts(dir)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return &recordingConnection{Connection: conn, w: f}, f, nil
+}
+
+// removeOldTranscripts deletes the oldest transcripts in dir exceeding maxFiles
+func (r *Recorder) removeOldTranscripts(dir string) error {
+	if r.maxFiles <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+transcriptSuffix))
+	if err != nil {
+		return err
+	}
+
+	// file names start with the time of the recording, so they sort by age
+	sort.Strings(files)
+	for len(files) > r.maxFiles {
+		err = os.Remove(files[0])
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "could not remove old transcript")
+		}
+		files = files[1:]
+	}
+
+	return nil
+}
+
+type recordingConnection struct {
+	Connection
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// RunCommand runs a command against the device and records it
+func (c *recordingConnection) RunCommand(ctx context.Context, cmd string) (string, error) {
+	out, err := c.Connection.RunCommand(ctx, cmd)
+	c.record(cmd, out, err)
+
+	return out, err
+}
+
+// RunCommandExpect runs a command against the device and records it
+func (c *recordingConnection) RunCommandExpect(ctx context.Context, cmd string, prompt *regexp.Regexp) (string, error) {
+	out, err := c.Connection.RunCommandExpect(ctx, cmd, prompt)
+	c.record(cmd, out, err)
+
+	return out, err
+}
+
+func (c *recordingConnection) record(cmd, out string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := &strings.Builder{}
+	b.WriteString(transcriptCommandPrefix + cmd + "\n")
+	if err != nil {
+		b.WriteString(transcriptErrorPrefix + err.Error() + "\n")
+	}
+	b.WriteString(out)
+	if len(out) > 0 && !strings.HasSuffix(out, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString(transcriptEnd + "\n")
+
+	io.WriteString(c.w, b.String())
+}
@@ -0,0 +1,192 @@
+package connector
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
+)
+
+// NewReplayConnection serves the command outputs recorded for device instead of connecting to it.
+// The outputs are read from a directory named after the device in the replay directory. It may contain
+// transcripts written by the Recorder and files with the output of a single command, named like the command
+// with every character besides letters, digits, dots and dashes replaced by underscores (e.g. show_version.txt).
+func NewReplayConnection(device *Device, cfg *config.Config) (*ReplayConnection, error) {
+	dir := cfg.ReplayDirectory
+	if device.DeviceConfig.ReplayDirectory != nil {
+		dir = *device.DeviceConfig.ReplayDirectory
+	}
+	if dir == "" {
+		return nil, errors.Errorf("no replay directory configured for %s", device.Host)
+	}
+
+	c := &ReplayConnection{
+		Host:    device.Host,
+		outputs: make(map[string]replayOutput),
+	}
+
+	err := c.load(filepath.Join(dir, fileNameForHost(device.Host)))
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ReplayConnection answers commands with recorded outputs
+type ReplayConnection struct {
+	Host    string
+	outputs map[string]replayOutput
+}
+
+type replayOutput struct {
+	output string
+	err    string
+}
+
+func (c *ReplayConnection) load(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+transcriptSuffix))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.Errorf("no recorded outputs found in %s", dir)
+	}
+
+	// newer transcripts replace the outputs of older ones, single command files replace transcripts
+	sort.Strings(files)
+	commandFiles := make([]string, 0)
+	for _, f := range files {
+		transcript, err := isTranscript(f)
+		if err != nil {
+			return err
+		}
+
+		if !transcript {
+			commandFiles = append(commandFiles, f)
+			continue
+		}
+
+		err = c.loadTranscript(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, f := range commandFiles {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return errors.Wrap(err, "could not read recorded output")
+		}
+
+		c.outputs[strings.TrimSuffix(filepath.Base(f), transcriptSuffix)] = replayOutput{output: string(b)}
+	}
+
+	return nil
+}
+
+func isTranscript(name string) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, errors.Wrap(err, "could not read recorded output")
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if strings.HasPrefix(s.Text(), transcriptCommandPrefix) {
+			return true, nil
+		}
+		if !strings.HasPrefix(s.Text(), "# ") {
+			return false, nil
+		}
+	}
+
+	return false, s.Err()
+}
+
+func (c *ReplayConnection) loadTranscript(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return errors.Wrap(err, "could not read transcript")
+	}
+	defer f.Close()
+
+	var cmd string
+	var out replayOutput
+	inCommand := false
+
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for s.Scan() {
+		line := s.Text()
+
+		switch {
+		case strings.HasPrefix(line, transcriptCommandPrefix):
+			cmd = strings.TrimPrefix(line, transcriptCommandPrefix)
+			out = replayOutput{}
+			inCommand = true
+		case !inCommand:
+		case line == transcriptEnd:
+			c.outputs[fileNameForCommand(cmd)] = out
+			inCommand = false
+		case out.output == "" && out.err == "" && strings.HasPrefix(line, transcriptErrorPrefix):
+			out.err = strings.TrimPrefix(line, transcriptErrorPrefix)
+		default:
+			out.output += line + "\n"
+		}
+	}
+
+	return errors.Wrapf(s.Err(), "could not parse transcript %s", name)
+}
+
+// RunCommand returns the recorded output of cmd
+func (c *ReplayConnection) RunCommand(ctx context.Context, cmd string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	out, found := c.outputs[fileNameForCommand(cmd)]
+	if !found {
+		// answer like a device not knowing the command
+		return cmd + "\n% Invalid input detected at '^' marker.\n\n" + c.Host + "#", nil
+	}
+
+	if out.err != "" {
+		return out.output, errors.New(out.err)
+	}
+
+	return out.output, nil
+}
+
+// RunCommandExpect returns the recorded output of cmd
+func (c *ReplayConnection) RunCommandExpect(ctx context.Context, cmd string, prompt *regexp.Regexp) (string, error) {
+	return c.RunCommand(ctx, cmd)
+}
+
+// Alive checks if the connection can still be used to run commands
+func (c *ReplayConnection) Alive() bool {
+	return true
+}
+
+func (c *ReplayConnection) String() string {
+	return c.Host
+}
+
+// Close closes connection
+func (c *ReplayConnection) Close() {
+}
+
+func fileNameForHost(host string) string {
+	return unsafeFileNameRegexp.ReplaceAllString(host, "_")
+}
+
+func fileNameForCommand(cmd string) string {
+	return unsafeFileNameRegexp.ReplaceAllString(strings.TrimSpace(cmd), "_")
+}
@@ -0,0 +1,135 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	restconfMediaType    = "application/yang-data+json"
+	restconfModulesState = "ietf-yang-library:modules-state"
+)
+
+// ErrRestconfNoData is returned when the requested resource does not exist on the device
+var ErrRestconfNoData = errors.New("no data")
+
+// NewRestconfClient creates a client for the RESTCONF API of device.
+// The YANG modules supported are read from the YANG library of the device, which also verifies access to the API.
+func NewRestconfClient(ctx context.Context, device *Device, cfg *config.Config) (*RestconfClient, error) {
+	timeout := cfg.Timeout
+	if device.DeviceConfig.Timeout != nil {
+		timeout = *device.DeviceConfig.Timeout
+	}
+
+	client, baseURL, err := newAPIClient(device.Host, device.Restconf, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not initialize RESTCONF client")
+	}
+
+	c := &RestconfClient{
+		Host:     device.Host,
+		url:      baseURL + "/restconf/data/",
+		username: device.Username,
+		password: device.Password,
+		client:   client,
+		modules:  make(map[string]bool),
+	}
+
+	err = c.loadModules(ctx)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// RestconfClient retrieves data from the RESTCONF API of a device
+type RestconfClient struct {
+	Host     string
+	url      string
+	username string
+	password string
+	client   *http.Client
+	modules  map[string]bool
+}
+
+type restconfModules struct {
+	ModulesState struct {
+		Modules []struct {
+			Name string `json:"name"`
+		} `json:"module"`
+	} `json:"ietf-yang-library:modules-state"`
+}
+
+func (c *RestconfClient) loadModules(ctx context.Context) error {
+	b, err := c.Get(ctx, restconfModulesState)
+	if err != nil {
+		return errors.Wrap(err, "could not read YANG library")
+	}
+
+	m := &restconfModules{}
+	err = json.Unmarshal(b, m)
+	if err != nil {
+		return errors.Wrapf(err, "could not parse YANG library of %s", c.Host)
+	}
+
+	for _, mod := range m.ModulesState.Modules {
+		c.modules[mod.Name] = true
+	}
+
+	return nil
+}
+
+// HasModule checks if the device supports the YANG module
+func (c *RestconfClient) HasModule(name string) bool {
+	return c.modules[name]
+}
+
+// Get retrieves the data resource at path (e.g. Cisco-IOS-XE-interfaces-oper:interfaces)
+func (c *RestconfClient) Get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", restconfMediaType)
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "RESTCONF request to %s failed", c.Host)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read RESTCONF response of %s", c.Host)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return b, nil
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil, errors.Wrapf(ErrRestconfNoData, "%s on %s", path, c.Host)
+	case http.StatusUnauthorized:
+		return nil, errors.Errorf("RESTCONF authentication failed for %s", c.Host)
+	}
+
+	return nil, errors.Errorf("RESTCONF request for %s to %s failed: %s", path, c.Host, resp.Status)
+}
+
+func (c *RestconfClient) String() string {
+	return c.Host
+}
+
+// Close closes idle HTTP connections to the device
+func (c *RestconfClient) Close() {
+	c.client.CloseIdleConnections()
+}
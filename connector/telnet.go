@@ -0,0 +1,247 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	telnetIAC  byte = 255
+	telnetDONT byte = 254
+	telnetDO   byte = 253
+	telnetWONT byte = 252
+	telnetWILL byte = 251
+	telnetSB   byte = 250
+	telnetSE   byte = 240
+
+	telnetOptEcho byte = 1
+	telnetOptSGA  byte = 3
+)
+
+var (
+	loginPromptRegexp    = regexp.MustCompile(`(?i)(username|login|password):\s?$`)
+	usernamePromptRegexp = regexp.MustCompile(`(?i)(username|login):\s?$`)
+	loginResultRegexp    = regexp.MustCompile(`(?i)((username|login|password):|.+[#>])\s?$`)
+)
+
+// NewTelnetConnection connects to device using telnet
+func NewTelnetConnection(ctx context.Context, device *Device, cfg *config.Config) (*TelnetConnection, error) {
+	c := &TelnetConnection{
+		Host: device.Host + ":" + device.Port,
+		cli:  cliForDevice(device, cfg),
+	}
+
+	err := c.Connect(ctx, device.Username, device.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// TelnetConnection encapsulates the telnet connection to the device
+type TelnetConnection struct {
+	cli
+	Host string
+	conn net.Conn
+}
+
+// Connect connects to the device and logs in
+func (c *TelnetConnection) Connect(ctx context.Context, username, password string) error {
+	d := &net.Dialer{Timeout: c.timeout}
+	conn, err := d.DialContext(ctx, "tcp", c.Host)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.closer = conn
+	c.stdin = &telnetWriter{w: conn}
+	c.stdout = &telnetReader{conn: conn}
+
+	err = c.login(ctx, username, password)
+	if err != nil {
+		c.Close()
+		return err
+	}
+
+	err = c.init(ctx)
+	if err != nil {
+		c.Close()
+		return err
+	}
+
+	return nil
+}
+
+func (c *TelnetConnection) login(ctx context.Context, username, password string) error {
+	out, err := c.expect(ctx, "", loginPromptRegexp)
+	if err != nil {
+		return errors.Wrap(err, "no login prompt received")
+	}
+
+	if usernamePromptRegexp.MatchString(out) {
+		out, err = c.send(ctx, username, "", loginResultRegexp)
+		if err != nil {
+			return errors.Wrap(err, "could not send username")
+		}
+	}
+
+	if passwordPromptRegexp.MatchString(out) {
+		out, err = c.send(ctx, password, "", loginResultRegexp)
+		if err != nil {
+			return errors.Wrap(err, "could not send password")
+		}
+	}
+
+	if loginPromptRegexp.MatchString(out) || !promptRegexp.MatchString(out) {
+		return errors.Errorf("telnet login to %s failed: %s", c.Host, strings.TrimSpace(out))
+	}
+
+	return nil
+}
+
+// Alive checks if the connection can still be used to run commands. Telnet has no keepalive, so an empty line is
+// sent and the prompt is expected within the timeout.
+func (c *TelnetConnection) Alive() bool {
+	if c.broken || c.conn == nil {
+		return false
+	}
+
+	_, err := c.RunCommand(context.Background(), "")
+	return err == nil
+}
+
+func (c *TelnetConnection) String() string {
+	return c.Host
+}
+
+// Close closes connection
+func (c *TelnetConnection) Close() {
+	if c.conn == nil {
+		return
+	}
+	c.conn.Close()
+}
+
+// telnetWriter sends lines terminated by CR LF
+type telnetWriter struct {
+	w io.Writer
+}
+
+func (t *telnetWriter) Write(p []byte) (int, error) {
+	_, err := t.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n")))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+const (
+	telnetStateData = iota
+	telnetStateIAC
+	telnetStateOption
+	telnetStateSub
+	telnetStateSubIAC
+)
+
+// telnetReader strips telnet commands from the data stream and answers option negotiation.
+// Only echo and suppress go ahead are accepted from the server, everything else is refused.
+type telnetReader struct {
+	conn    net.Conn
+	state   int
+	command byte
+	buf     []byte
+}
+
+func (t *telnetReader) Read(p []byte) (int, error) {
+	if len(t.buf) < len(p) {
+		t.buf = make([]byte, len(p))
+	}
+
+	for {
+		n, err := t.conn.Read(t.buf[:len(p)])
+		if n == 0 {
+			return 0, err
+		}
+
+		out := 0
+		for _, b := range t.buf[:n] {
+			if t.process(b) {
+				p[out] = b
+				out++
+			}
+		}
+
+		if out > 0 || err != nil {
+			return out, err
+		}
+	}
+}
+
+// process handles one byte read from the connection and returns if it is data
+func (t *telnetReader) process(b byte) bool {
+	switch t.state {
+	case telnetStateIAC:
+		switch b {
+		case telnetIAC:
+			t.state = telnetStateData
+			return true
+		case telnetDO, telnetDONT, telnetWILL, telnetWONT:
+			t.command = b
+			t.state = telnetStateOption
+		case telnetSB:
+			t.state = telnetStateSub
+		default:
+			t.state = telnetStateData
+		}
+	case telnetStateOption:
+		t.negotiate(t.command, b)
+		t.state = telnetStateData
+	case telnetStateSub:
+		if b == telnetIAC {
+			t.state = telnetStateSubIAC
+		}
+	case telnetStateSubIAC:
+		if b == telnetSE {
+			t.state = telnetStateData
+		} else {
+			t.state = telnetStateSub
+		}
+	default:
+		if b == telnetIAC {
+			t.state = telnetStateIAC
+			return false
+		}
+		return b != 0
+	}
+
+	return false
+}
+
+func (t *telnetReader) negotiate(command, option byte) {
+	var reply byte
+	switch command {
+	case telnetDO:
+		reply = telnetWONT
+		if option == telnetOptSGA {
+			reply = telnetWILL
+		}
+	case telnetWILL:
+		reply = telnetDONT
+		if option == telnetOptEcho || option == telnetOptSGA {
+			reply = telnetDO
+		}
+	default:
+		return
+	}
+
+	t.conn.Write([]byte{telnetIAC, reply, option})
+}
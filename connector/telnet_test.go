@@ -0,0 +1,118 @@
+package connector
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/config"
+)
+
+// telnetStandIn accepts one telnet login and answers every line with the prompt until hang is closed
+type telnetStandIn struct {
+	listener net.Listener
+	conns    chan net.Conn
+	hang     chan struct{}
+}
+
+func newTelnetStandIn(t *testing.T) *telnetStandIn {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	s := &telnetStandIn{listener: l, conns: make(chan net.Conn, 1), hang: make(chan struct{})}
+	go s.serve()
+
+	return s
+}
+
+func (s *telnetStandIn) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	s.conns <- conn
+
+	r := bufio.NewReader(conn)
+	io.WriteString(conn, "Username: ")
+	r.ReadString('\n')
+	io.WriteString(conn, "Password: ")
+	r.ReadString('\n')
+	io.WriteString(conn, "\r\nswitch1#")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		select {
+		case <-s.hang:
+			continue
+		default:
+		}
+
+		io.WriteString(conn, strings.TrimRight(line, "\r\n")+"\r\nswitch1#")
+	}
+}
+
+func connectTelnetStandIn(t *testing.T, s *telnetStandIn) *TelnetConnection {
+	t.Helper()
+
+	cfg := config.New()
+	cfg.Timeout = 1
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	device := &Device{Host: host, Port: port, Username: "admin", Password: "admin", DeviceConfig: &config.DeviceConfig{Host: host}}
+
+	c, err := NewTelnetConnection(context.Background(), device, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+func TestTelnetConnectionAlive(t *testing.T) {
+	t.Run("answering", func(t *testing.T) {
+		c := connectTelnetStandIn(t, newTelnetStandIn(t))
+		if !c.Alive() {
+			t.Fatal("connection to answering device is not alive")
+		}
+
+		out, err := c.RunCommand(context.Background(), "show clock")
+		if err != nil || !strings.Contains(out, "show clock") {
+			t.Errorf("got %q (%v) after the probe, want the echoed command", out, err)
+		}
+	})
+
+	t.Run("closed by device", func(t *testing.T) {
+		s := newTelnetStandIn(t)
+		c := connectTelnetStandIn(t, s)
+		(<-s.conns).Close()
+
+		if c.Alive() {
+			t.Error("connection closed by the device is alive")
+		}
+	})
+
+	t.Run("not answering", func(t *testing.T) {
+		s := newTelnetStandIn(t)
+		c := connectTelnetStandIn(t, s)
+		close(s.hang)
+
+		if c.Alive() {
+			t.Error("connection to device not answering is alive")
+		}
+		if c.Alive() {
+			t.Error("connection is alive after the probe timed out")
+		}
+	})
+}
@@ -2,11 +2,13 @@ package main
 
 import (
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/lwlcom/cisco_exporter/config"
 	"github.com/lwlcom/cisco_exporter/connector"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 func devicesForConfig(cfg *config.Config) ([]*connector.Device, error) {
@@ -27,13 +29,71 @@ func devicesForConfig(cfg *config.Config) ([]*connector.Device, error) {
 	return devs, nil
 }
 
-func deviceFromDeviceConfig(device *config.DeviceConfig, hostname string, cfg *config.Config) (*connector.Device, error) {
-	auth, err := authForDevice(device, cfg)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not initialize config for device %s", device.Host)
+// checkHostKeyVerification makes sure the host keys of every device which can open an SSH session are verified or
+// skipping the verification was chosen explicitly, which is logged as a warning
+func checkHostKeyVerification(cfg *config.Config) error {
+	for _, d := range cfg.Devices {
+		if !connector.UsesSSH(transportForDevice(d, cfg)) {
+			continue
+		}
+
+		if cfg.SkipsHostKeyVerify(d) {
+			log.Warnf("Host keys of %s are not verified (insecure_skip_host_key_verify)", d.Host)
+			continue
+		}
+
+		if cfg.KnownHostsFileForDevice(d) == "" {
+			return errors.Errorf("no known_hosts_file set for device %s: SSH host keys are verified against it "+
+				"(set insecure_skip_host_key_verify to connect without verification)", d.Host)
+		}
+	}
+
+	return nil
+}
+
+// transportForDevice returns the transport configured for device, empty if the default (SSH) is used
+func transportForDevice(device *config.DeviceConfig, cfg *config.Config) string {
+	if device.Transport != "" {
+		return device.Transport
 	}
 
+	return cfg.Transport
+}
+
+func deviceFromDeviceConfig(device *config.DeviceConfig, hostname string, cfg *config.Config) (*connector.Device, error) {
+	transport := connector.TransportSSH
 	port := "22"
+	if t := transportForDevice(device, cfg); t != "" {
+		transport = t
+	}
+	switch transport {
+	case connector.TransportSSH, connector.TransportNetconf, connector.TransportRestconf, connector.TransportGNMI:
+	case connector.TransportTelemetry:
+		if cfg.Telemetry.ListenAddress == "" {
+			return nil, errors.Errorf("telemetry receiver is not enabled for device %s", device.Host)
+		}
+	case connector.TransportTelnet:
+		port = "23"
+	case connector.TransportReplay, connector.TransportNXAPI:
+	default:
+		return nil, errors.Errorf("unknown transport %q for device %s", transport, device.Host)
+	}
+
+	var auth []connector.AuthMethod
+	var jumpHost *connector.JumpHost
+	if transport != connector.TransportReplay && transport != connector.TransportNXAPI {
+		var err error
+		auth, err = authForDevice(device, cfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not initialize config for device %s", device.Host)
+		}
+
+		jumpHost, err = jumpHostForDevice(device, cfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not initialize jump host for device %s", device.Host)
+		}
+	}
+
 	host := hostname
 	if strings.Contains(host, ":") {
 		d := strings.Split(host, ":")
@@ -41,48 +101,246 @@ func deviceFromDeviceConfig(device *config.DeviceConfig, hostname string, cfg *c
 		port = d[1]
 	}
 
+	netconfPort := cfg.NetconfPort
+	if device.NetconfPort != nil {
+		netconfPort = *device.NetconfPort
+	}
+
+	user, password := credentialsForDevice(device, cfg)
+	if transport == connector.TransportNXAPI && password == "" {
+		return nil, errors.Errorf("NX-API requires a password for device %s", device.Host)
+	}
+	if transport == connector.TransportRestconf && password == "" {
+		return nil, errors.Errorf("RESTCONF requires a password for device %s", device.Host)
+	}
+	if transport == connector.TransportGNMI && password == "" {
+		return nil, errors.Errorf("gNMI requires a password for device %s", device.Host)
+	}
+
+	nxapi := cfg.NXAPI
+	if device.NXAPI != nil {
+		nxapi = device.NXAPI
+	}
+
+	restconf := cfg.Restconf
+	if device.Restconf != nil {
+		restconf = device.Restconf
+	}
+
+	gnmi := gnmiConfigForDevice(device, cfg)
+
+	telemetryNode := host
+	if device.TelemetryNode != nil {
+		telemetryNode = *device.TelemetryNode
+	}
+
 	return &connector.Device{
-		Host:         host,
-		Port:         port,
-		Auth:         auth,
-		DeviceConfig: device,
+		Host:          host,
+		Port:          port,
+		NetconfPort:   strconv.Itoa(netconfPort),
+		NXAPI:         nxapi,
+		Restconf:      restconf,
+		TelemetryNode: telemetryNode,
+		GNMI:          gnmi,
+		Transport:     transport,
+		Username:      user,
+		Password:      password,
+		Auth:          auth,
+		JumpHost:      jumpHost,
+		DeviceConfig:  device,
 	}, nil
 }
 
-func authForDevice(device *config.DeviceConfig, cfg *config.Config) (connector.AuthMethod, error) {
+// gnmiConfigForDevice returns the gNMI settings of device. Settings missing in the device config are taken from the global config.
+func gnmiConfigForDevice(device *config.DeviceConfig, cfg *config.Config) *config.GNMIConfig {
+	if device.GNMI == nil {
+		return cfg.GNMI
+	}
+
+	gc := *device.GNMI
+	if gc.Port == 0 {
+		gc.Port = cfg.GNMI.Port
+	}
+	if gc.Mode == "" {
+		gc.Mode = cfg.GNMI.Mode
+	}
+	if gc.Encoding == "" {
+		gc.Encoding = cfg.GNMI.Encoding
+	}
+	if gc.SampleInterval == 0 {
+		gc.SampleInterval = cfg.GNMI.SampleInterval
+	}
+
+	return &gc
+}
+
+func credentialsForDevice(device *config.DeviceConfig, cfg *config.Config) (string, string) {
 	user := cfg.Username
 	if device.Username != nil {
 		user = *device.Username
 	}
 
+	password := cfg.Password
+	if device.Password != nil {
+		password = *device.Password
+	}
+
+	return user, password
+}
+
+func authForDevice(device *config.DeviceConfig, cfg *config.Config) ([]connector.AuthMethod, error) {
+	user, password := credentialsForDevice(device, cfg)
+
+	order := cfg.AuthMethods
+	if device.AuthMethods != nil {
+		order = device.AuthMethods
+	}
+	if len(order) == 0 {
+		order = []string{"agent", "key", "password"}
+	}
+
+	sshAgent := cfg.SSHAgent
+	if device.SSHAgent != nil {
+		sshAgent = *device.SSHAgent
+	}
+
+	keyFile := cfg.KeyFile
 	if device.KeyFile != nil {
-		return authForKeyFile(user, *device.KeyFile)
+		keyFile = *device.KeyFile
 	}
 
-	if cfg.KeyFile != "" {
-		return authForKeyFile(user, cfg.KeyFile)
+	methods := make([]connector.AuthMethod, 0)
+	for _, name := range order {
+		switch name {
+		case "agent":
+			if sshAgent {
+				methods = append(methods, connector.AuthByAgent(user))
+			}
+		case "key":
+			if keyFile == "" {
+				continue
+			}
+
+			passphrase, err := keyPassphraseForDevice(device, cfg)
+			if err != nil {
+				return nil, err
+			}
+
+			auth, err := authForKeyFile(user, keyFile, passphrase)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, auth)
+		case "password":
+			if password != "" {
+				methods = append(methods, connector.AuthByPassword(user, password))
+			}
+		default:
+			return nil, errors.Errorf("unknown authentication method %q", name)
+		}
 	}
 
-	if device.Password != nil {
-		return connector.AuthByPassword(user, *device.Password), nil
+	if len(methods) == 0 {
+		return nil, errors.New("no valid authentication method available")
+	}
+
+	return methods, nil
+}
+
+func keyPassphraseForDevice(device *config.DeviceConfig, cfg *config.Config) ([]byte, error) {
+	if device.KeyPassphrase != nil {
+		return []byte(*device.KeyPassphrase), nil
+	}
+
+	if device.KeyPassphraseFile != nil {
+		return readPassphraseFile(*device.KeyPassphraseFile)
+	}
+
+	if cfg.KeyPassphrase != "" {
+		return []byte(cfg.KeyPassphrase), nil
+	}
+
+	if cfg.KeyPassphraseFile != "" {
+		return readPassphraseFile(cfg.KeyPassphraseFile)
+	}
+
+	return nil, nil
+}
+
+func readPassphraseFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read key passphrase file")
 	}
 
-	if cfg.Password != "" {
-		return connector.AuthByPassword(user, cfg.Password), nil
+	return []byte(strings.TrimRight(string(b), "\r\n")), nil
+}
+
+func jumpHostForDevice(device *config.DeviceConfig, cfg *config.Config) (*connector.JumpHost, error) {
+	if device.JumpHost != nil {
+		return jumpHostFromConfig(device.JumpHost, cfg)
+	}
+
+	return jumpHostFromConfig(cfg.JumpHost, cfg)
+}
+
+func jumpHostFromConfig(jc *config.JumpHostConfig, cfg *config.Config) (*connector.JumpHost, error) {
+	if jc == nil {
+		return nil, nil
+	}
+
+	j := &connector.JumpHost{
+		Host:     jc.Host,
+		Port:     "22",
+		Username: cfg.Username,
+	}
+
+	if jc.Port != 0 {
+		j.Port = strconv.Itoa(jc.Port)
+	}
+
+	if jc.Username != "" {
+		j.Username = jc.Username
+	}
+
+	if jc.SSHAgent {
+		j.Auth = append(j.Auth, connector.AuthByAgent(j.Username))
+	}
+
+	if jc.KeyFile != "" {
+		auth, err := authForKeyFile(j.Username, jc.KeyFile, []byte(jc.KeyPassphrase))
+		if err != nil {
+			return nil, err
+		}
+		j.Auth = append(j.Auth, auth)
+	}
+
+	if jc.Password != "" {
+		j.Auth = append(j.Auth, connector.AuthByPassword(j.Username, jc.Password))
+	}
+
+	if len(j.Auth) == 0 {
+		return nil, errors.Errorf("no valid authentication method available for jump host %s", jc.Host)
+	}
+
+	parent, err := jumpHostFromConfig(jc.JumpHost, cfg)
+	if err != nil {
+		return nil, err
 	}
+	j.JumpHost = parent
 
-	return nil, errors.New("no valid authentication method available")
+	return j, nil
 }
 
-func authForKeyFile(username, keyFile string) (connector.AuthMethod, error) {
+func authForKeyFile(username, keyFile string, passphrase []byte) (connector.AuthMethod, error) {
 	f, err := os.Open(keyFile)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not open ssh key file")
+		return connector.AuthMethod{}, errors.Wrap(err, "could not open ssh key file")
 	}
 	defer f.Close()
-	auth, err := connector.AuthByKey(username, f)
+	auth, err := connector.AuthByKeyWithPassphrase(username, f, passphrase)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not load ssh private key file")
+		return connector.AuthMethod{}, errors.Wrap(err, "could not load ssh private key file")
 	}
 
 	return auth, nil
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/lwlcom/cisco_exporter/connector"
+)
+
+func TestCheckHostKeyVerification(t *testing.T) {
+	skip, knownHosts := true, "/etc/cisco_exporter/known_hosts"
+
+	tests := []struct {
+		name    string
+		global  config.Config
+		device  config.DeviceConfig
+		wantErr bool
+	}{
+		{name: "no known hosts file", device: config.DeviceConfig{Host: "r1"}, wantErr: true},
+		{name: "global known hosts file", global: config.Config{KnownHostsFile: knownHosts}, device: config.DeviceConfig{Host: "r1"}},
+		{name: "device known hosts file", device: config.DeviceConfig{Host: "r1", KnownHostsFile: &knownHosts}},
+		{name: "skipped globally", global: config.Config{SkipHostKeyVerify: true}, device: config.DeviceConfig{Host: "r1"}},
+		{name: "skipped for device", device: config.DeviceConfig{Host: "r1", SkipHostKeyVerify: &skip}},
+		{name: "gnmi falls back to ssh", device: config.DeviceConfig{Host: "r1", Transport: "gnmi"}, wantErr: true},
+		{name: "restconf falls back to ssh", global: config.Config{Transport: "restconf"}, device: config.DeviceConfig{Host: "r1"}, wantErr: true},
+		{name: "telnet", device: config.DeviceConfig{Host: "r1", Transport: "telnet"}},
+		{name: "replay", device: config.DeviceConfig{Host: "r1", Transport: "replay"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := test.global
+			c.Devices = []*config.DeviceConfig{&test.device}
+
+			err := checkHostKeyVerification(&c)
+			if test.wantErr && (err == nil || !strings.Contains(err.Error(), "known_hosts_file")) {
+				t.Errorf("got %v, want an error about the known_hosts_file", err)
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestDevicesForRequest(t *testing.T) {
+	defer func(c *config.Config, d []*connector.Device) { cfg, devices = c, d }(cfg, devices)
+
+	cfg = config.New()
+	cfg.Transport = connector.TransportReplay
+	cfg.Devices = []*config.DeviceConfig{{Host: "r1"}, {Host: `sw\d+`, IsHostPattern: true, HostPattern: regexp.MustCompile(`^sw\d+$`)}}
+	cfg.Modules = map[string]*config.ModuleConfig{"core": {}}
+
+	var err error
+	devices, err = devicesForConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "all devices", query: "", want: []string{"r1"}},
+		{name: "configured device", query: "target=r1", want: []string{"r1"}},
+		{name: "host pattern", query: "target=sw1", want: []string{"sw1"}},
+		{name: "unknown target", query: "target=r2", wantErr: true},
+		{name: "unknown target with module", query: "target=r2&module=core", want: []string{"r2"}},
+		{name: "unknown target with unknown module", query: "target=r2&module=edge", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/metrics?"+test.query, nil)
+
+			devs, err := devicesForRequest(r)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %d devices, want an error", len(devs))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hosts := make([]string, len(devs))
+			for i, d := range devs {
+				hosts[i] = d.Host
+			}
+			if !reflect.DeepEqual(hosts, test.want) {
+				t.Errorf("got %v, want %v", hosts, test.want)
+			}
+		})
+	}
+}
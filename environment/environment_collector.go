@@ -50,25 +50,10 @@ func (*environmentCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect collects metrics from Cisco
 func (c *environmentCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	var envcmd string
-
-	switch client.OSType {
-	case rpc.IOS, rpc.NXOS:
-		envcmd = "show environment"
-	case rpc.IOSXE:
-		envcmd = "show environment all"
-	}
-	out, err := client.RunCommand(envcmd)
+	items, err := c.items(client, labelValues)
 	if err != nil {
 		return err
 	}
-	items, err := c.Parse(client.OSType, out)
-	if err != nil {
-		if client.Debug {
-			log.Printf("Parse environment for %s: %s\n", labelValues[0], err.Error())
-		}
-		return nil
-	}
 
 	for _, item := range items {
 		l := append(labelValues, item.Name)
@@ -99,3 +84,43 @@ func (c *environmentCollector) Collect(client *rpc.Client, ch chan<- prometheus.
 
 	return nil
 }
+
+func (c *environmentCollector) items(client *rpc.Client, labelValues []string) ([]EnvironmentItem, error) {
+	if supportsGNMI(client) {
+		return c.itemsGNMI(client)
+	}
+
+	if supportsNetconf(client) {
+		return c.itemsNetconf(client)
+	}
+
+	if supportsRestconf(client) {
+		return c.itemsRestconf(client)
+	}
+
+	if client.OSType == rpc.NXOS && client.NXAPI != nil {
+		return c.itemsNXAPI(client)
+	}
+
+	var envcmd string
+
+	switch client.OSType {
+	case rpc.IOS, rpc.NXOS, rpc.IOSXR:
+		envcmd = "show environment"
+	case rpc.IOSXE:
+		envcmd = "show environment all"
+	}
+	out, err := client.RunCommand(envcmd)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.Parse(client.OSType, out)
+	if err != nil {
+		if client.Debug {
+			log.Printf("Parse environment for %s: %s\n", labelValues[0], err.Error())
+		}
+		return nil, nil
+	}
+
+	return items, nil
+}
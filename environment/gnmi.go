@@ -0,0 +1,84 @@
+package environment
+
+import (
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const openconfigPlatformModel = "openconfig-platform"
+
+type gnmiComponents struct {
+	Components struct {
+		Components []struct {
+			Name  string `json:"name"`
+			State struct {
+				Type        string `json:"type"`
+				OperStatus  string `json:"oper-status"`
+				Temperature *struct {
+					Instant     rpc.JSONFloat `json:"instant"`
+					AlarmStatus bool          `json:"alarm-status"`
+				} `json:"temperature"`
+			} `json:"state"`
+		} `json:"component"`
+	} `json:"components"`
+}
+
+// supportsGNMI checks if the environment can be retrieved using gNMI
+func supportsGNMI(client *rpc.Client) bool {
+	return client.HasGNMIModel(openconfigPlatformModel)
+}
+
+// itemsGNMI retrieves the temperature sensors, power supplies and fans from the components of openconfig-platform
+func (c *environmentCollector) itemsGNMI(client *rpc.Client) ([]EnvironmentItem, error) {
+	data := &gnmiComponents{}
+	err := client.GNMIGet("/components", data)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []EnvironmentItem{}
+	for _, comp := range data.Components.Components {
+		s := comp.State
+		status := strings.ToLower(identityName(s.OperStatus))
+
+		switch identityName(s.Type) {
+		case "POWER_SUPPLY":
+			items = append(items, EnvironmentItem{
+				Name:   comp.Name,
+				OK:     status == "active",
+				Status: status,
+			})
+		case "FAN":
+			items = append(items, EnvironmentItem{
+				Name:   comp.Name,
+				IsFan:  true,
+				OK:     status == "active",
+				Status: status,
+			})
+		default:
+			if s.Temperature == nil {
+				continue
+			}
+
+			status = "normal"
+			if s.Temperature.AlarmStatus {
+				status = "alarm"
+			}
+			items = append(items, EnvironmentItem{
+				Name:        comp.Name,
+				IsTemp:      true,
+				OK:          !s.Temperature.AlarmStatus,
+				Status:      status,
+				Temperature: float64(s.Temperature.Instant),
+			})
+		}
+	}
+
+	return items, nil
+}
+
+// identityName removes the module from an identity like openconfig-platform-types:POWER_SUPPLY
+func identityName(s string) string {
+	return s[strings.LastIndex(s, ":")+1:]
+}
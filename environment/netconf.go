@@ -0,0 +1,63 @@
+package environment
+
+import (
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const environmentOperNamespace = "http://cisco.com/ns/yang/Cisco-IOS-XE-environment-oper"
+
+// environmentSensor is an entry of environment-sensors in Cisco-IOS-XE-environment-oper
+type environmentSensor struct {
+	Name           string  `xml:"name" json:"name"`
+	Location       string  `xml:"location" json:"location"`
+	State          string  `xml:"state" json:"state"`
+	CurrentReading float64 `xml:"current-reading" json:"current-reading"`
+	SensorUnits    string  `xml:"sensor-units" json:"sensor-units"`
+}
+
+type environmentSensors struct {
+	Sensors []environmentSensor `xml:"environment-sensors>environment-sensor"`
+}
+
+// supportsNetconf checks if the environment can be retrieved using NETCONF
+func supportsNetconf(client *rpc.Client) bool {
+	return client.HasNetconfCapability(environmentOperNamespace)
+}
+
+// itemsNetconf retrieves the temperature and fan sensors using Cisco-IOS-XE-environment-oper
+func (c *environmentCollector) itemsNetconf(client *rpc.Client) ([]EnvironmentItem, error) {
+	data := &environmentSensors{}
+	err := client.NetconfGet(`<environment-sensors xmlns="`+environmentOperNamespace+`"/>`, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return sensorItems(data.Sensors), nil
+}
+
+// sensorItems converts temperature and fan sensors to items, other sensors (e.g. voltage) are ignored
+func sensorItems(sensors []environmentSensor) []EnvironmentItem {
+	items := []EnvironmentItem{}
+	for _, s := range sensors {
+		units := strings.ToLower(s.SensorUnits)
+		isTemp := strings.Contains(units, "celsius")
+		isFan := strings.Contains(units, "rpm")
+		if !isTemp && !isFan {
+			continue
+		}
+
+		state := strings.ToLower(strings.TrimSpace(s.State))
+		items = append(items, EnvironmentItem{
+			Name:        strings.TrimSpace(s.Location + " " + s.Name),
+			IsTemp:      isTemp,
+			IsFan:       isFan,
+			OK:          state == "normal" || state == "good" || state == "ok" || state == "green",
+			Status:      state,
+			Temperature: s.CurrentReading,
+		})
+	}
+
+	return items
+}
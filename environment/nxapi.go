@@ -0,0 +1,98 @@
+package environment
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+type nxapiEnvironment struct {
+	Fans struct {
+		Table struct {
+			Rows json.RawMessage `json:"ROW_faninfo"`
+		} `json:"TABLE_faninfo"`
+	} `json:"fandetails"`
+	PowerSupplies struct {
+		Table struct {
+			Rows json.RawMessage `json:"ROW_psinfo"`
+		} `json:"TABLE_psinfo"`
+	} `json:"powersup"`
+	Temperatures struct {
+		Rows json.RawMessage `json:"ROW_tempinfo"`
+	} `json:"TABLE_tempinfo"`
+}
+
+type nxapiFan struct {
+	Name   string `json:"fanname"`
+	Status string `json:"fanstatus"`
+}
+
+type nxapiPowerSupply struct {
+	Number json.Number `json:"psnum"`
+	Model  string      `json:"psmodel"`
+	Status string      `json:"ps_status"`
+}
+
+type nxapiTemperature struct {
+	Module      json.Number   `json:"tempmod"`
+	Sensor      string        `json:"sensor"`
+	Current     rpc.JSONFloat `json:"curtemp"`
+	AlarmStatus string        `json:"alarmstatus"`
+}
+
+// itemsNXAPI retrieves the temperature, power supply and fan status using the structured output of NX-API
+func (c *environmentCollector) itemsNXAPI(client *rpc.Client) ([]EnvironmentItem, error) {
+	data := &nxapiEnvironment{}
+	err := client.RunCommandJSON("show environment", data)
+	if err != nil {
+		return nil, err
+	}
+
+	temperatures := []nxapiTemperature{}
+	err = rpc.UnmarshalRows(data.Temperatures.Rows, &temperatures)
+	if err != nil {
+		return nil, err
+	}
+	powerSupplies := []nxapiPowerSupply{}
+	err = rpc.UnmarshalRows(data.PowerSupplies.Table.Rows, &powerSupplies)
+	if err != nil {
+		return nil, err
+	}
+	fans := []nxapiFan{}
+	err = rpc.UnmarshalRows(data.Fans.Table.Rows, &fans)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []EnvironmentItem{}
+	for _, t := range temperatures {
+		status := strings.ToLower(strings.TrimSpace(t.AlarmStatus))
+		items = append(items, EnvironmentItem{
+			Name:        strings.TrimSpace(t.Module.String() + " " + t.Sensor),
+			IsTemp:      true,
+			OK:          status == "ok",
+			Status:      status,
+			Temperature: float64(t.Current),
+		})
+	}
+	for _, p := range powerSupplies {
+		status := strings.ToLower(strings.TrimSpace(p.Status))
+		items = append(items, EnvironmentItem{
+			Name:   strings.TrimSpace(p.Number.String() + " " + p.Model),
+			OK:     status == "ok",
+			Status: status,
+		})
+	}
+	for _, f := range fans {
+		status := strings.ToLower(strings.TrimSpace(f.Status))
+		items = append(items, EnvironmentItem{
+			Name:   strings.TrimSpace(f.Name),
+			IsFan:  true,
+			OK:     status == "ok",
+			Status: status,
+		})
+	}
+
+	return items, nil
+}
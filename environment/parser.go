@@ -10,6 +10,9 @@ import (
 
 // Parse parses cli output using textfsm and tries to find all temperature, power & fan related data
 func (c *environmentCollector) Parse(ostype string, output string) ([]EnvironmentItem, error) {
+	if ostype == rpc.IOSXR {
+		return c.parseIOSXR(output)
+	}
 	if ostype != rpc.IOSXE && ostype != rpc.IOS {
 		return nil, errors.New("'show environment' is not implemented for " + ostype)
 	}
@@ -87,3 +90,70 @@ func (c *environmentCollector) Parse(ostype string, output string) ([]Environmen
 	}
 	return items, nil
 }
+
+// parseIOSXR parses the output of show environment on IOS XR. The state of temperature sensors is derived from their
+// thresholds, fans are ok if all of them are spinning.
+func (c *environmentCollector) parseIOSXR(output string) ([]EnvironmentItem, error) {
+	items := []EnvironmentItem{}
+
+	results_temp, err := util.ParseTextfsm(templ_temp_iosxr, output)
+	if err != nil {
+		return items, errors.New("Error parsing via templ_temp_iosxr: " + err.Error())
+	}
+	results_power, err := util.ParseTextfsm(templ_power_iosxr, output)
+	if err != nil {
+		return items, errors.New("Error parsing via templ_power_iosxr: " + err.Error())
+	}
+	results_fan, err := util.ParseTextfsm(templ_fan_iosxr, output)
+	if err != nil {
+		return items, errors.New("Error parsing via templ_fan_iosxr: " + err.Error())
+	}
+	for _, result := range results_temp {
+		value := util.Str2float64(result["VALUE"].(string))
+		state := "normal"
+		if exceeds(value, result["MAJOR_LO"].(string), result["MAJOR_HI"].(string)) {
+			state = "major"
+		} else if exceeds(value, result["MINOR_LO"].(string), result["MINOR_HI"].(string)) {
+			state = "minor"
+		}
+		x := EnvironmentItem{
+			Name:        strings.TrimSpace(result["LOCATION"].(string) + " " + result["SENSOR"].(string)),
+			IsTemp:      true,
+			OK:          state == "normal",
+			Status:      state,
+			Temperature: value,
+		}
+		items = append(items, x)
+	}
+	for _, result := range results_power {
+		status := strings.ToLower(strings.TrimSpace(result["STATUS"].(string)))
+		x := EnvironmentItem{
+			Name:   strings.TrimSpace(result["LOCATION"].(string) + " " + result["MODEL"].(string)),
+			IsTemp: false,
+			OK:     status == "ok",
+			Status: status,
+		}
+		items = append(items, x)
+	}
+	for _, result := range results_fan {
+		status := "ok"
+		for _, speed := range strings.Fields(result["SPEEDS"].(string)) {
+			if util.Str2float64(speed) <= 0 {
+				status = "failed"
+			}
+		}
+		x := EnvironmentItem{
+			Name:   strings.TrimSpace(result["LOCATION"].(string) + " " + result["MODEL"].(string)),
+			IsFan:  true,
+			OK:     status == "ok",
+			Status: status,
+		}
+		items = append(items, x)
+	}
+	return items, nil
+}
+
+// exceeds checks if value is outside of the thresholds, which are NA if not defined
+func exceeds(value float64, low, high string) bool {
+	return (low != "NA" && value < util.Str2float64(low)) || (high != "NA" && value > util.Str2float64(high))
+}
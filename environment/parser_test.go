@@ -0,0 +1,41 @@
+package environment
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+func TestParseIOSXR(t *testing.T) {
+	output, err := os.ReadFile("../cmd/fakecisco/fixtures/iosxr/show_environment.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &environmentCollector{}
+	items, err := c.Parse(rpc.IOSXR, string(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []EnvironmentItem{
+		{Name: "0/RSP0/CPU0 TEMP_CPU", Status: "normal", OK: true, IsTemp: true, Temperature: 42},
+		{Name: "0/RSP0/CPU0 TEMP_INLET", Status: "normal", OK: true, IsTemp: true, Temperature: 28},
+		{Name: "0/0/CPU0 TEMP_ASIC", Status: "major", OK: false, IsTemp: true, Temperature: 97},
+		{Name: "0/PM0 Inlet Temperature", Status: "normal", OK: true, IsTemp: true, Temperature: 25},
+		{Name: "0/PM0 Hotspot Temperature", Status: "normal", OK: true, IsTemp: true, Temperature: 33},
+		{Name: "0/PM0 NC55-1100W-ACFW", Status: "ok", OK: true},
+		{Name: "0/PM1 NC55-1100W-ACFW", Status: "failed", OK: false},
+		// the fans of the power modules are covered by their power status
+		{Name: "0/FT0 NC55-FAN-FW", Status: "ok", OK: true, IsFan: true},
+		{Name: "0/FT1 NC55-FAN-FW", Status: "failed", OK: false, IsFan: true},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(items), len(want), items)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("got %+v, want %+v", items[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package environment
+
+import (
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const environmentOperModule = "Cisco-IOS-XE-environment-oper"
+
+type restconfEnvironmentSensors struct {
+	EnvironmentSensors struct {
+		Sensors []environmentSensor `json:"environment-sensor"`
+	} `json:"Cisco-IOS-XE-environment-oper:environment-sensors"`
+}
+
+// supportsRestconf checks if the environment can be retrieved using RESTCONF
+func supportsRestconf(client *rpc.Client) bool {
+	return client.HasRestconfModule(environmentOperModule)
+}
+
+// itemsRestconf retrieves the temperature and fan sensors using Cisco-IOS-XE-environment-oper
+func (c *environmentCollector) itemsRestconf(client *rpc.Client) ([]EnvironmentItem, error) {
+	data := &restconfEnvironmentSensors{}
+	err := client.RestconfGet(environmentOperModule+":environment-sensors", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return sensorItems(data.EnvironmentSensors.Sensors), nil
+}
@@ -118,3 +118,69 @@ Fan_C9200L
   ^\s*${LOCATION}\s+${NAME}\s+${VALUE}\s+${STATUS} -> Record
   ^$ -> Start
 `
+
+/*
+ * # IOS XR (64 bit)
+ * ================================================================================
+ * Location  TEMPERATURE                 Value   Crit Major Minor Minor Major  Crit
+ *           Sensor                    (deg C)   (Lo) (Lo)  (Lo)  (Hi)  (Hi)   (Hi)
+ * --------------------------------------------------------------------------------
+ * 0/RP0/CPU0
+ *           TEMP_CPU                       42    -10    -5     0    90    95   100
+ *           TEMP_INLET                     28    -10    -5     0    55    60    65
+ * 0/PM0
+ *           Inlet Temperature              25    -10    -5     0    60    65    70
+ */
+var templ_temp_iosxr = `# show environment
+Value Filldown LOCATION (\d+\/\S+)
+Value Required SENSOR (\S+(?: \S+)*)
+Value VALUE (-?\d+)
+Value MINOR_LO (-?\d+|NA)
+Value MINOR_HI (-?\d+|NA)
+Value MAJOR_LO (-?\d+|NA)
+Value MAJOR_HI (-?\d+|NA)
+
+Start
+  ^Location\s+TEMPERATURE -> Temperature
+
+Temperature
+  ^${LOCATION}\s*$
+  ^\s+${SENSOR}\s+${VALUE}\s+-?\d+\s+${MAJOR_LO}\s+${MINOR_LO}\s+${MINOR_HI}\s+${MAJOR_HI}\s+-?\d+ -> Record
+  ^$ -> End
+`
+
+/*
+ * # IOS XR (64 bit)
+ * ================================================================================
+ *    Power       Supply         ------Input----   ------Output---     Status
+ *    Module      Type           Volts     Amps    Volts     Amps
+ * ================================================================================
+ *    0/PM0       NC55-1100W-ACFW 233.0     0.6    12.1     9.1      OK
+ *    0/PM1       NC55-1100W-ACFW 0.0       0.0    0.0      0.0      FAILED
+ */
+var templ_power_iosxr = `# show environment
+Value LOCATION (\d+\/PM\d+)
+Value MODEL ([\w\-\.]+)
+Value STATUS (\w+(?: \w+)*)
+
+Start
+  ^\s+${LOCATION}\s+${MODEL}\s+[\d\.]+\s+[\d\.]+\s+[\d\.]+\s+[\d\.]+\s+${STATUS}\s* -> Record
+`
+
+/*
+ * # IOS XR (64 bit)
+ * ================================================================================
+ *                                      Fan speed (rpm)
+ * Location       FRU Type            FAN_0    FAN_1    FAN_2
+ * --------------------------------------------------------------------------------
+ * 0/FT0          NC55-FAN-FW          7980     7920     7950
+ * 0/FT1          NC55-FAN-FW          8010        0     7890
+ */
+var templ_fan_iosxr = `# show environment
+Value LOCATION (\d+\/FT\d+)
+Value MODEL ([\w\-\.]+)
+Value SPEEDS ((?:\s+(?:\d+|-))+)
+
+Start
+  ^${LOCATION}\s+${MODEL}${SPEEDS}\s* -> Record
+`
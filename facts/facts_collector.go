@@ -2,6 +2,7 @@ package facts
 
 import (
 	"log"
+	"math"
 
 	"github.com/lwlcom/cisco_exporter/rpc"
 
@@ -59,11 +60,7 @@ func (*factsCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // CollectVersion collects version informations from Cisco
 func (c *factsCollector) CollectVersion(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	out, err := client.RunCommand("show version")
-	if err != nil {
-		return err
-	}
-	item, err := c.ParseVersion(client.OSType, out)
+	item, err := c.version(client)
 	if err != nil {
 		return err
 	}
@@ -74,11 +71,7 @@ func (c *factsCollector) CollectVersion(client *rpc.Client, ch chan<- prometheus
 
 // CollectMemory collects memory informations from Cisco
 func (c *factsCollector) CollectMemory(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	out, err := client.RunCommand("show process memory")
-	if err != nil {
-		return err
-	}
-	items, err := c.ParseMemory(client.OSType, out)
+	items, err := c.memory(client)
 	if err != nil {
 		return err
 	}
@@ -93,21 +86,93 @@ func (c *factsCollector) CollectMemory(client *rpc.Client, ch chan<- prometheus.
 
 // CollectCPU collects cpu informations from Cisco
 func (c *factsCollector) CollectCPU(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	out, err := client.RunCommand("show process cpu")
+	item, err := c.cpu(client)
 	if err != nil {
 		return err
 	}
-	item, err := c.ParseCPU(client.OSType, out)
-	if err != nil {
-		return err
+	values := map[*prometheus.Desc]float64{
+		cpuOneMinuteDesc:   item.OneMinute,
+		cpuFiveSecondsDesc: item.FiveSeconds,
+		cpuInterruptsDesc:  item.Interrupts,
+		cpuFiveMinutesDesc: item.FiveMinutes,
+	}
+	for desc, v := range values {
+		// values not reported by the device are NaN
+		if !math.IsNaN(v) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, labelValues...)
+		}
 	}
-	ch <- prometheus.MustNewConstMetric(cpuOneMinuteDesc, prometheus.GaugeValue, item.OneMinute, labelValues...)
-	ch <- prometheus.MustNewConstMetric(cpuFiveSecondsDesc, prometheus.GaugeValue, item.FiveSeconds, labelValues...)
-	ch <- prometheus.MustNewConstMetric(cpuInterruptsDesc, prometheus.GaugeValue, item.Interrupts, labelValues...)
-	ch <- prometheus.MustNewConstMetric(cpuFiveMinutesDesc, prometheus.GaugeValue, item.FiveMinutes, labelValues...)
 	return nil
 }
 
+func (c *factsCollector) version(client *rpc.Client) (VersionFact, error) {
+	if client.Info != nil && client.Info.Version != "" {
+		// show version was already run to identify the device
+		return VersionFact{Version: client.OSType + "-" + client.Info.Version}, nil
+	}
+
+	if client.HasRestconfModule(deviceHardwareOperModule) {
+		return c.versionRestconf(client)
+	}
+
+	out, err := client.RunCommand("show version")
+	if err != nil {
+		return VersionFact{}, err
+	}
+
+	return c.ParseVersion(client.OSType, out)
+}
+
+func (c *factsCollector) memory(client *rpc.Client) ([]MemoryFact, error) {
+	if client.HasTelemetryPath(telemetryMemoryPath) || client.HasTelemetryPath(telemetryResourcesPath) {
+		return c.memoryTelemetry(client)
+	}
+
+	if client.HasRestconfModule(memoryOperModule) {
+		return c.memoryRestconf(client)
+	}
+
+	cmd := "show process memory"
+	switch client.OSType {
+	case rpc.IOSXR:
+		cmd = "show memory summary"
+	case rpc.ASA:
+		cmd = "show memory"
+	}
+
+	out, err := client.RunCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ParseMemory(client.OSType, out)
+}
+
+func (c *factsCollector) cpu(client *rpc.Client) (CPUFact, error) {
+	if client.HasTelemetryPath(telemetryCPUPath) || client.HasTelemetryPath(telemetryResourcesPath) {
+		return c.cpuTelemetry(client)
+	}
+
+	if client.HasRestconfModule(processCPUOperModule) {
+		return c.cpuRestconf(client)
+	}
+
+	cmd := "show process cpu"
+	switch client.OSType {
+	case rpc.IOSXR:
+		cmd = "show processes cpu"
+	case rpc.ASA:
+		cmd = "show cpu usage"
+	}
+
+	out, err := client.RunCommand(cmd)
+	if err != nil {
+		return CPUFact{}, err
+	}
+
+	return c.ParseCPU(client.OSType, out)
+}
+
 // Collect collects metrics from Cisco
 func (c *factsCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
 	err := c.CollectVersion(client, ch, labelValues)
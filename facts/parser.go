@@ -2,6 +2,7 @@ package facts
 
 import (
 	"errors"
+	"math"
 	"regexp"
 	"strings"
 
@@ -11,13 +12,15 @@ import (
 
 // ParseVersion parses cli output and tries to find the version number of the running OS
 func (c *factsCollector) ParseVersion(ostype string, output string) (VersionFact, error) {
-	if ostype != rpc.IOSXE && ostype != rpc.NXOS && ostype != rpc.IOS {
+	if ostype != rpc.IOSXE && ostype != rpc.NXOS && ostype != rpc.IOS && ostype != rpc.IOSXR && ostype != rpc.ASA {
 		return VersionFact{}, errors.New("'show version' is not implemented for " + ostype)
 	}
 	versionRegexp := make(map[string]*regexp.Regexp)
 	versionRegexp[rpc.IOSXE], _ = regexp.Compile(`^Cisco IOS.+XE Software.*, Version ([^\s]+).*$`)
 	versionRegexp[rpc.IOS], _ = regexp.Compile(`^.*, Version (.+),.*$`)
 	versionRegexp[rpc.NXOS], _ = regexp.Compile(`^\s+NXOS: version (.*)$`)
+	versionRegexp[rpc.IOSXR], _ = regexp.Compile(`^Cisco IOS XR Software, Version ([^\s\[]+).*$`)
+	versionRegexp[rpc.ASA], _ = regexp.Compile(`^(?:Cisco Adaptive Security Appliance Software|Model\s+: .+ Threat Defense \(\d+\)) Version (\S+).*$`)
 
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
@@ -32,6 +35,12 @@ func (c *factsCollector) ParseVersion(ostype string, output string) (VersionFact
 
 // ParseMemory parses cli output and tries to find current memory usage
 func (c *factsCollector) ParseMemory(ostype string, output string) ([]MemoryFact, error) {
+	if ostype == rpc.IOSXR {
+		return c.parseMemoryIOSXR(output)
+	}
+	if ostype == rpc.ASA {
+		return c.parseMemoryASA(output)
+	}
 	if ostype != rpc.IOSXE && ostype != rpc.IOS {
 		return nil, errors.New("'show process memory' is not implemented for " + ostype)
 	}
@@ -57,6 +66,12 @@ func (c *factsCollector) ParseMemory(ostype string, output string) ([]MemoryFact
 
 // ParseCPU parses cli output and tries to find current CPU utilization
 func (c *factsCollector) ParseCPU(ostype string, output string) (CPUFact, error) {
+	if ostype == rpc.IOSXR {
+		return c.parseCPUIOSXR(output)
+	}
+	if ostype == rpc.ASA {
+		return c.parseCPUASA(output)
+	}
 	if ostype != rpc.IOSXE && ostype != rpc.IOS {
 		return CPUFact{}, errors.New("'show process cpu' is not implemented for " + ostype)
 	}
@@ -77,3 +92,86 @@ func (c *factsCollector) ParseCPU(ostype string, output string) (CPUFact, error)
 	}
 	return CPUFact{}, errors.New("Version string not found")
 }
+
+// parseMemoryIOSXR parses the output of show memory summary on IOS XR, which reports memory in megabytes
+func (c *factsCollector) parseMemoryIOSXR(output string) ([]MemoryFact, error) {
+	memoryRegexp := regexp.MustCompile(`^\s*(Physical|Application) Memory\s*: (\d+)M(?: total)? \((\d+)M available\)`)
+
+	items := []MemoryFact{}
+	for _, line := range strings.Split(output, "\n") {
+		matches := memoryRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		total := util.Str2float64(matches[2]) * 1024 * 1024
+		free := util.Str2float64(matches[3]) * 1024 * 1024
+		items = append(items, MemoryFact{
+			Type:  matches[1],
+			Total: total,
+			Used:  total - free,
+			Free:  free,
+		})
+	}
+	return items, nil
+}
+
+// parseCPUIOSXR parses the output of show processes cpu on IOS XR, which does not report five seconds and interrupts
+func (c *factsCollector) parseCPUIOSXR(output string) (CPUFact, error) {
+	cpuRegexp := regexp.MustCompile(`^\s*CPU utilization for one minute: (\d+)%; five minutes: (\d+)%; fifteen minutes: (\d+)%.*$`)
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := cpuRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		return CPUFact{
+			FiveSeconds: math.NaN(),
+			Interrupts:  math.NaN(),
+			OneMinute:   util.Str2float64(matches[1]),
+			FiveMinutes: util.Str2float64(matches[2]),
+		}, nil
+	}
+	return CPUFact{}, errors.New("CPU utilization not found")
+}
+
+// parseMemoryASA parses the output of show memory on ASA
+func (c *factsCollector) parseMemoryASA(output string) ([]MemoryFact, error) {
+	memoryRegexp := regexp.MustCompile(`^(Free|Used|Total) memory:\s+(\d+) bytes`)
+
+	values := make(map[string]float64)
+	for _, line := range strings.Split(output, "\n") {
+		matches := memoryRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		values[matches[1]] = util.Str2float64(matches[2])
+	}
+	if len(values) != 3 {
+		return nil, errors.New("Memory usage not found")
+	}
+	return []MemoryFact{{
+		Type:  "System",
+		Total: values["Total"],
+		Used:  values["Used"],
+		Free:  values["Free"],
+	}}, nil
+}
+
+// parseCPUASA parses the output of show cpu usage on ASA, which does not report interrupts
+func (c *factsCollector) parseCPUASA(output string) (CPUFact, error) {
+	cpuRegexp := regexp.MustCompile(`^\s*CPU utilization for 5 seconds = (\d+)%; 1 minute: (\d+)%; 5 minutes: (\d+)%.*$`)
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := cpuRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		return CPUFact{
+			FiveSeconds: util.Str2float64(matches[1]),
+			Interrupts:  math.NaN(),
+			OneMinute:   util.Str2float64(matches[2]),
+			FiveMinutes: util.Str2float64(matches[3]),
+		}, nil
+	}
+	return CPUFact{}, errors.New("CPU utilization not found")
+}
@@ -0,0 +1,65 @@
+package facts
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	output, err := os.ReadFile("../cmd/fakecisco/fixtures/iosxr/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output)
+}
+
+func TestParseMemoryIOSXR(t *testing.T) {
+	c := &factsCollector{}
+	items, err := c.ParseMemory(rpc.IOSXR, readFixture(t, "show_memory_summary.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const mb = 1024 * 1024
+	want := []MemoryFact{
+		{Type: "Physical", Total: 12288 * mb, Used: (12288 - 8123) * mb, Free: 8123 * mb},
+		{Type: "Application", Total: 11932 * mb, Used: (11932 - 8123) * mb, Free: 8123 * mb},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d memory facts, want %d", len(items), len(want))
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("got %+v, want %+v", items[i], want[i])
+		}
+	}
+}
+
+func TestParseCPUIOSXR(t *testing.T) {
+	c := &factsCollector{}
+	cpu, err := c.ParseCPU(rpc.IOSXR, readFixture(t, "show_processes_cpu.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu.OneMinute != 7 || cpu.FiveMinutes != 6 {
+		t.Errorf("got one minute %v five minutes %v, want 7 and 6", cpu.OneMinute, cpu.FiveMinutes)
+	}
+	if !math.IsNaN(cpu.FiveSeconds) || !math.IsNaN(cpu.Interrupts) {
+		t.Errorf("got five seconds %v interrupts %v, want NaN", cpu.FiveSeconds, cpu.Interrupts)
+	}
+}
+
+func TestParseVersionIOSXR(t *testing.T) {
+	c := &factsCollector{}
+	version, err := c.ParseVersion(rpc.IOSXR, readFixture(t, "show_version.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.Version != "IOSXR-6.5.3" {
+		t.Errorf("got version %q, want IOSXR-6.5.3", version.Version)
+	}
+}
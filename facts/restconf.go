@@ -0,0 +1,109 @@
+package facts
+
+import (
+	"regexp"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const (
+	deviceHardwareOperModule = "Cisco-IOS-XE-device-hardware-oper"
+	memoryOperModule         = "Cisco-IOS-XE-memory-oper"
+	processCPUOperModule     = "Cisco-IOS-XE-process-cpu-oper"
+)
+
+var restconfVersionRegexp = regexp.MustCompile(`Version ([^\s,]+)`)
+
+type restconfSystemData struct {
+	SystemData struct {
+		SoftwareVersion string `json:"software-version"`
+	} `json:"Cisco-IOS-XE-device-hardware-oper:device-system-data"`
+}
+
+type restconfMemoryStatistics struct {
+	MemoryStatistics struct {
+		MemoryStatistics []memoryStatistic `json:"memory-statistic"`
+	} `json:"Cisco-IOS-XE-memory-oper:memory-statistics"`
+}
+
+// memoryStatistic is a memory pool of Cisco-IOS-XE-memory-oper
+type memoryStatistic struct {
+	Name        string        `json:"name"`
+	TotalMemory rpc.JSONFloat `json:"total-memory"`
+	UsedMemory  rpc.JSONFloat `json:"used-memory"`
+	FreeMemory  rpc.JSONFloat `json:"free-memory"`
+}
+
+type restconfCPUUtilization struct {
+	CPUUtilization cpuUtilization `json:"Cisco-IOS-XE-process-cpu-oper:cpu-utilization"`
+}
+
+// cpuUtilization is the CPU utilization of Cisco-IOS-XE-process-cpu-oper
+type cpuUtilization struct {
+	FiveSeconds     rpc.JSONFloat `json:"five-seconds"`
+	FiveSecondsIntr rpc.JSONFloat `json:"five-seconds-intr"`
+	OneMinute       rpc.JSONFloat `json:"one-minute"`
+	FiveMinutes     rpc.JSONFloat `json:"five-minutes"`
+}
+
+// versionRestconf retrieves the running version using Cisco-IOS-XE-device-hardware-oper
+func (c *factsCollector) versionRestconf(client *rpc.Client) (VersionFact, error) {
+	data := &restconfSystemData{}
+	err := client.RestconfGet(deviceHardwareOperModule+":device-hardware-data/device-hardware/device-system-data", data)
+	if err != nil {
+		return VersionFact{}, err
+	}
+
+	version := data.SystemData.SoftwareVersion
+	if m := restconfVersionRegexp.FindStringSubmatch(version); m != nil {
+		version = m[1]
+	}
+
+	return VersionFact{Version: version}, nil
+}
+
+// memoryRestconf retrieves the memory pools using Cisco-IOS-XE-memory-oper
+func (c *factsCollector) memoryRestconf(client *rpc.Client) ([]MemoryFact, error) {
+	data := &restconfMemoryStatistics{}
+	err := client.RestconfGet(memoryOperModule+":memory-statistics", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return memoryStatisticItems(data.MemoryStatistics.MemoryStatistics), nil
+}
+
+func memoryStatisticItems(statistics []memoryStatistic) []MemoryFact {
+	items := []MemoryFact{}
+	for _, m := range statistics {
+		items = append(items, MemoryFact{
+			Type:  m.Name,
+			Total: float64(m.TotalMemory),
+			Used:  float64(m.UsedMemory),
+			Free:  float64(m.FreeMemory),
+		})
+	}
+
+	return items
+}
+
+// cpuRestconf retrieves the CPU utilization using Cisco-IOS-XE-process-cpu-oper.
+// Unlike the per core load of Cisco-IOS-XE-platform-software-oper it reports the same values as show process cpu.
+func (c *factsCollector) cpuRestconf(client *rpc.Client) (CPUFact, error) {
+	data := &restconfCPUUtilization{}
+	err := client.RestconfGet(processCPUOperModule+":cpu-usage/cpu-utilization", data)
+	if err != nil {
+		return CPUFact{}, err
+	}
+
+	return data.CPUUtilization.fact(), nil
+}
+
+func (u cpuUtilization) fact() CPUFact {
+	return CPUFact{
+		FiveSeconds: float64(u.FiveSeconds),
+		Interrupts:  float64(u.FiveSecondsIntr),
+		OneMinute:   float64(u.OneMinute),
+		FiveMinutes: float64(u.FiveMinutes),
+	}
+}
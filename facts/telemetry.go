@@ -0,0 +1,92 @@
+package facts
+
+import (
+	"errors"
+	"math"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const (
+	telemetryMemoryPath    = memoryOperModule + ":memory-statistics/memory-statistic"
+	telemetryCPUPath       = processCPUOperModule + ":cpu-usage/cpu-utilization"
+	telemetryResourcesPath = "show system resources"
+)
+
+// systemResources is the output of show system resources on NX-OS, memory is given in KB
+type systemResources struct {
+	CPUStateUser     rpc.JSONFloat `json:"cpu_state_user"`
+	CPUStateKernel   rpc.JSONFloat `json:"cpu_state_kernel"`
+	MemoryUsageTotal rpc.JSONFloat `json:"memory_usage_total"`
+	MemoryUsageUsed  rpc.JSONFloat `json:"memory_usage_used"`
+	MemoryUsageFree  rpc.JSONFloat `json:"memory_usage_free"`
+}
+
+// memoryTelemetry retrieves the memory pools from the telemetry streamed by the device
+func (c *factsCollector) memoryTelemetry(client *rpc.Client) ([]MemoryFact, error) {
+	if client.HasTelemetryPath(telemetryMemoryPath) {
+		rows := []memoryStatistic{}
+		err := client.TelemetryRows(telemetryMemoryPath, &rows)
+		if err != nil {
+			return nil, err
+		}
+
+		return memoryStatisticItems(rows), nil
+	}
+
+	r, err := c.systemResources(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return []MemoryFact{
+		{
+			Type:  "system",
+			Total: float64(r.MemoryUsageTotal) * 1024,
+			Used:  float64(r.MemoryUsageUsed) * 1024,
+			Free:  float64(r.MemoryUsageFree) * 1024,
+		},
+	}, nil
+}
+
+// cpuTelemetry retrieves the CPU utilization from the telemetry streamed by the device.
+// NX-OS only reports the current utilization, which is used as five second value.
+func (c *factsCollector) cpuTelemetry(client *rpc.Client) (CPUFact, error) {
+	if client.HasTelemetryPath(telemetryCPUPath) {
+		rows := []cpuUtilization{}
+		err := client.TelemetryRows(telemetryCPUPath, &rows)
+		if err != nil {
+			return CPUFact{}, err
+		}
+		if len(rows) == 0 {
+			return CPUFact{}, errors.New("no CPU utilization received")
+		}
+
+		return rows[0].fact(), nil
+	}
+
+	r, err := c.systemResources(client)
+	if err != nil {
+		return CPUFact{}, err
+	}
+
+	return CPUFact{
+		FiveSeconds: float64(r.CPUStateUser + r.CPUStateKernel),
+		Interrupts:  math.NaN(),
+		OneMinute:   math.NaN(),
+		FiveMinutes: math.NaN(),
+	}, nil
+}
+
+func (c *factsCollector) systemResources(client *rpc.Client) (*systemResources, error) {
+	rows := []systemResources{}
+	err := client.TelemetryRows(telemetryResourcesPath, &rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no system resources received")
+	}
+
+	return &rows[0], nil
+}
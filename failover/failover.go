@@ -0,0 +1,24 @@
+package failover
+
+// Failover is the failover state of an ASA
+type Failover struct {
+	Enabled   bool
+	LANLinkUp bool
+	ThisHost  Unit
+	OtherHost Unit
+}
+
+// Unit is one of the units of a failover pair
+type Unit struct {
+	Unit       string
+	State      string
+	ActiveTime float64
+	Interfaces []MonitoredInterface
+}
+
+// MonitoredInterface is an interface monitored by failover, in multiple context mode prefixed with its context
+type MonitoredInterface struct {
+	Context string
+	Name    string
+	Status  string
+}
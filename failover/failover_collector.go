@@ -0,0 +1,105 @@
+package failover
+
+import (
+	"log"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+
+	"github.com/lwlcom/cisco_exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "cisco_failover_"
+
+var (
+	enabledDesc         *prometheus.Desc
+	lanLinkUpDesc       *prometheus.Desc
+	unitStateDesc       *prometheus.Desc
+	unitActiveDesc      *prometheus.Desc
+	activeTimeDesc      *prometheus.Desc
+	interfaceNormalDesc *prometheus.Desc
+)
+
+func init() {
+	l := []string{"target"}
+	enabledDesc = prometheus.NewDesc(prefix+"enabled", "Failover is enabled (1 = On, 0 = Off)", l, nil)
+	lanLinkUpDesc = prometheus.NewDesc(prefix+"lan_link_up", "Failover LAN interface is up (1 = up, 0 = down)", l, nil)
+
+	l = append(l, "host", "unit")
+	unitActiveDesc = prometheus.NewDesc(prefix+"unit_active", "Unit is the active unit (1 = Active, 0 = otherwise)", l, nil)
+	activeTimeDesc = prometheus.NewDesc(prefix+"unit_active_time_seconds", "Time the unit was active in seconds", l, nil)
+	unitStateDesc = prometheus.NewDesc(prefix+"unit_state", "Failover state of the unit (always 1)", append(l, "state"), nil)
+	interfaceNormalDesc = prometheus.NewDesc(prefix+"interface_normal", "Interface monitored by failover is in state Normal (1 = Normal, 0 = otherwise)", append(l, "context", "name", "status"), nil)
+}
+
+type failoverCollector struct {
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	return &failoverCollector{}
+}
+
+// Name returns the name of the collector
+func (*failoverCollector) Name() string {
+	return "Failover"
+}
+
+// Describe describes the metrics
+func (*failoverCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- enabledDesc
+	ch <- lanLinkUpDesc
+	ch <- unitStateDesc
+	ch <- unitActiveDesc
+	ch <- activeTimeDesc
+	ch <- interfaceNormalDesc
+}
+
+// Collect collects metrics from Cisco
+func (c *failoverCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	out, err := client.RunCommand("show failover")
+	if err != nil {
+		return err
+	}
+	f, err := c.Parse(client.OSType, out)
+	if err != nil {
+		if client.Debug {
+			log.Printf("Parse failover for %s: %s\n", labelValues[0], err.Error())
+		}
+		return nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(enabledDesc, prometheus.GaugeValue, boolToFloat(f.Enabled), labelValues...)
+	if !f.Enabled {
+		return nil
+	}
+	ch <- prometheus.MustNewConstMetric(lanLinkUpDesc, prometheus.GaugeValue, boolToFloat(f.LANLinkUp), labelValues...)
+
+	c.collectForUnit(ch, append(labelValues, "this"), &f.ThisHost)
+	c.collectForUnit(ch, append(labelValues, "other"), &f.OtherHost)
+
+	return nil
+}
+
+func (c *failoverCollector) collectForUnit(ch chan<- prometheus.Metric, labelValues []string, u *Unit) {
+	if u.Unit == "" {
+		return
+	}
+
+	l := append(labelValues, u.Unit)
+	ch <- prometheus.MustNewConstMetric(unitActiveDesc, prometheus.GaugeValue, boolToFloat(u.State == "Active"), l...)
+	ch <- prometheus.MustNewConstMetric(activeTimeDesc, prometheus.CounterValue, u.ActiveTime, l...)
+	ch <- prometheus.MustNewConstMetric(unitStateDesc, prometheus.GaugeValue, 1, append(l, u.State)...)
+
+	for _, i := range u.Interfaces {
+		ch <- prometheus.MustNewConstMetric(interfaceNormalDesc, prometheus.GaugeValue, boolToFloat(i.Status == "Normal"), append(l, i.Context, i.Name, i.Status)...)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
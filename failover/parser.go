@@ -0,0 +1,63 @@
+package failover
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+	"github.com/lwlcom/cisco_exporter/util"
+)
+
+// Parse parses cli output of show failover and returns the failover state
+func (c *failoverCollector) Parse(ostype string, output string) (*Failover, error) {
+	if ostype != rpc.ASA {
+		return nil, errors.New("'show failover' is not implemented for " + ostype)
+	}
+	// Failover On
+	// Failover LAN Interface: folink GigabitEthernet0/3 (up)
+	//         This host: Primary - Active
+	//                 Active time: 1234567 (sec)
+	//                   Interface outside (203.0.113.2): Normal (Monitored)
+	//                   admin Interface inside (10.0.0.1): Normal (Monitored)
+	//         Other host: Secondary - Standby Ready
+	enabledRegexp := regexp.MustCompile(`^Failover (On|Off)\s*$`)
+	lanLinkRegexp := regexp.MustCompile(`^Failover LAN Interface: .+ \((up|down)\)`)
+	hostRegexp := regexp.MustCompile(`^\s+(This|Other) host: (\S+) - (.+?)\s*$`)
+	activeTimeRegexp := regexp.MustCompile(`^\s+Active time: (\d+) \(sec\)`)
+	interfaceRegexp := regexp.MustCompile(`^\s+(?:(\S+) )?Interface (\S+) \(.*\): (.+?) \((?:Monitored|Not-Monitored|Waiting)\)`)
+
+	f := &Failover{}
+	found := false
+	var current *Unit
+	for _, line := range strings.Split(output, "\n") {
+		if matches := enabledRegexp.FindStringSubmatch(line); matches != nil {
+			f.Enabled = matches[1] == "On"
+			found = true
+		} else if matches := lanLinkRegexp.FindStringSubmatch(line); matches != nil {
+			f.LANLinkUp = matches[1] == "up"
+		} else if matches := hostRegexp.FindStringSubmatch(line); matches != nil {
+			current = &f.ThisHost
+			if matches[1] == "Other" {
+				current = &f.OtherHost
+			}
+			current.Unit = matches[2]
+			current.State = matches[3]
+		} else if current == nil {
+			continue
+		} else if matches := activeTimeRegexp.FindStringSubmatch(line); matches != nil {
+			current.ActiveTime = util.Str2float64(matches[1])
+		} else if matches := interfaceRegexp.FindStringSubmatch(line); matches != nil {
+			current.Interfaces = append(current.Interfaces, MonitoredInterface{
+				Context: matches[1],
+				Name:    matches[2],
+				Status:  matches[3],
+			})
+		}
+	}
+	if !found {
+		return nil, errors.New("Failover state not found")
+	}
+
+	return f, nil
+}
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const openconfigInterfacesModel = "openconfig-interfaces"
+
+type gnmiInterfaces struct {
+	Interfaces struct {
+		Interfaces []openconfigInterface `json:"interface"`
+	} `json:"interfaces"`
+}
+
+// supportsGNMI checks if the interfaces can be retrieved using gNMI
+func supportsGNMI(client *rpc.Client) bool {
+	return client.HasGNMIModel(openconfigInterfacesModel)
+}
+
+// interfacesGNMI retrieves the interfaces using openconfig-interfaces
+func (c *interfaceCollector) interfacesGNMI(client *rpc.Client) ([]Interface, error) {
+	data := &gnmiInterfaces{}
+	err := client.GNMIGet("/interfaces", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return openconfigInterfaceItems(data.Interfaces.Interfaces), nil
+}
@@ -81,28 +81,61 @@ func (*interfaceCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect collects metrics from Cisco
 func (c *interfaceCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
-	out, err := client.RunCommand("show interface")
+	items, err := c.interfaces(client, labelValues)
 	if err != nil {
 		return err
 	}
+
+	for _, item := range items {
+		c.collectForInterface(item, ch, labelValues)
+	}
+
+	return nil
+}
+
+func (c *interfaceCollector) interfaces(client *rpc.Client, labelValues []string) ([]Interface, error) {
+	if supportsTelemetry(client) {
+		return c.interfacesTelemetry(client)
+	}
+
+	if supportsGNMI(client) {
+		return c.interfacesGNMI(client)
+	}
+
+	if supportsNetconf(client) {
+		return c.interfacesNetconf(client)
+	}
+
+	if supportsRestconf(client) {
+		return c.interfacesRestconf(client)
+	}
+
+	if client.OSType == rpc.NXOS && client.NXAPI != nil {
+		return c.interfacesNXAPI(client)
+	}
+
+	out, err := client.RunCommand("show interface")
+	if err != nil {
+		return nil, err
+	}
 	items, err := c.Parse(client.OSType, out)
 	if err != nil {
 		if client.Debug {
 			log.Printf("Parse interfaces for %s: %s\n", labelValues[0], err.Error())
 		}
-		return nil
+		return nil, nil
 	}
 	if client.OSType == rpc.IOSXE {
 		out, err := client.RunCommand("show vlans")
 		if err != nil {
-			return err
+			return nil, err
 		}
 		vlans, err := c.ParseVlans(client.OSType, out)
 		if err != nil {
 			if client.Debug {
 				log.Printf("Parse vlans for %s: %s\n", labelValues[0], err.Error())
 			}
-			return nil
+			return nil, nil
 		}
 		for _, vlan := range vlans {
 			for i, item := range items {
@@ -115,11 +148,7 @@ func (c *interfaceCollector) Collect(client *rpc.Client, ch chan<- prometheus.Me
 		}
 	}
 
-	for _, item := range items {
-		c.collectForInterface(item, ch, labelValues)
-	}
-
-	return nil
+	return items, nil
 }
 
 func (c *interfaceCollector) collectForInterface(item Interface, ch chan<- prometheus.Metric, labelValues []string) {
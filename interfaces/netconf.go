@@ -0,0 +1,187 @@
+package interfaces
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+	"github.com/lwlcom/cisco_exporter/util"
+)
+
+const (
+	openconfigInterfacesNamespace = "http://openconfig.net/yang/interfaces"
+	ietfInterfacesNamespace       = "urn:ietf:params:xml:ns:yang:ietf-interfaces"
+)
+
+var portSpeedRegexp = regexp.MustCompile(`SPEED_(\d+)(MB|GB)$`)
+
+type openconfigInterfaces struct {
+	Interfaces []openconfigInterface `xml:"interfaces>interface"`
+}
+
+// openconfigInterface is an interface of openconfig-interfaces, which is used by NETCONF and gNMI
+type openconfigInterface struct {
+	State struct {
+		Name        string `xml:"name" json:"name"`
+		Description string `xml:"description" json:"description"`
+		AdminStatus string `xml:"admin-status" json:"admin-status"`
+		OperStatus  string `xml:"oper-status" json:"oper-status"`
+		Counters    struct {
+			InOctets        rpc.JSONFloat `xml:"in-octets" json:"in-octets"`
+			InBroadcastPkts rpc.JSONFloat `xml:"in-broadcast-pkts" json:"in-broadcast-pkts"`
+			InMulticastPkts rpc.JSONFloat `xml:"in-multicast-pkts" json:"in-multicast-pkts"`
+			InDiscards      rpc.JSONFloat `xml:"in-discards" json:"in-discards"`
+			InErrors        rpc.JSONFloat `xml:"in-errors" json:"in-errors"`
+			OutOctets       rpc.JSONFloat `xml:"out-octets" json:"out-octets"`
+			OutDiscards     rpc.JSONFloat `xml:"out-discards" json:"out-discards"`
+			OutErrors       rpc.JSONFloat `xml:"out-errors" json:"out-errors"`
+		} `xml:"counters" json:"counters"`
+	} `xml:"state" json:"state"`
+	Ethernet struct {
+		State struct {
+			MacAddress          string `xml:"mac-address" json:"mac-address"`
+			PortSpeed           string `xml:"port-speed" json:"port-speed"`
+			NegotiatedPortSpeed string `xml:"negotiated-port-speed" json:"negotiated-port-speed"`
+		} `xml:"state" json:"state"`
+	} `xml:"ethernet" json:"ethernet"`
+}
+
+type ietfInterfaces struct {
+	Config []struct {
+		Name        string `xml:"name"`
+		Description string `xml:"description"`
+	} `xml:"interfaces>interface"`
+	State []struct {
+		Name        string  `xml:"name"`
+		AdminStatus string  `xml:"admin-status"`
+		OperStatus  string  `xml:"oper-status"`
+		PhysAddress string  `xml:"phys-address"`
+		Speed       float64 `xml:"speed"`
+		Statistics  struct {
+			InOctets        float64 `xml:"in-octets"`
+			InBroadcastPkts float64 `xml:"in-broadcast-pkts"`
+			InMulticastPkts float64 `xml:"in-multicast-pkts"`
+			InDiscards      float64 `xml:"in-discards"`
+			InErrors        float64 `xml:"in-errors"`
+			OutOctets       float64 `xml:"out-octets"`
+			OutDiscards     float64 `xml:"out-discards"`
+			OutErrors       float64 `xml:"out-errors"`
+		} `xml:"statistics"`
+	} `xml:"interfaces-state>interface"`
+}
+
+// supportsNetconf checks if the interfaces can be retrieved using NETCONF
+func supportsNetconf(client *rpc.Client) bool {
+	return client.HasNetconfCapability(openconfigInterfacesNamespace) || client.HasNetconfCapability(ietfInterfacesNamespace)
+}
+
+// interfacesNetconf retrieves the interfaces using openconfig-interfaces or ietf-interfaces
+func (c *interfaceCollector) interfacesNetconf(client *rpc.Client) ([]Interface, error) {
+	if client.HasNetconfCapability(openconfigInterfacesNamespace) {
+		return c.interfacesOpenconfig(client)
+	}
+
+	return c.interfacesIETF(client)
+}
+
+func (c *interfaceCollector) interfacesOpenconfig(client *rpc.Client) ([]Interface, error) {
+	data := &openconfigInterfaces{}
+	err := client.NetconfGet(`<interfaces xmlns="`+openconfigInterfacesNamespace+`"/>`, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return openconfigInterfaceItems(data.Interfaces), nil
+}
+
+func openconfigInterfaceItems(ifaces []openconfigInterface) []Interface {
+	items := []Interface{}
+	for _, i := range ifaces {
+		e := i.Ethernet.State
+		speed := e.NegotiatedPortSpeed
+		if speed == "" {
+			speed = e.PortSpeed
+		}
+
+		s := i.State
+		items = append(items, Interface{
+			Name:           s.Name,
+			Description:    s.Description,
+			MacAddress:     ciscoMacAddress(e.MacAddress),
+			AdminStatus:    strings.ToLower(s.AdminStatus),
+			OperStatus:     strings.ToLower(s.OperStatus),
+			InputBytes:     float64(s.Counters.InOctets),
+			InputErrors:    float64(s.Counters.InErrors),
+			InputDrops:     float64(s.Counters.InDiscards),
+			InputBroadcast: float64(s.Counters.InBroadcastPkts),
+			InputMulticast: float64(s.Counters.InMulticastPkts),
+			OutputBytes:    float64(s.Counters.OutOctets),
+			OutputErrors:   float64(s.Counters.OutErrors),
+			OutputDrops:    float64(s.Counters.OutDiscards),
+			Speed:          portSpeed(speed),
+		})
+	}
+
+	return items
+}
+
+func (c *interfaceCollector) interfacesIETF(client *rpc.Client) ([]Interface, error) {
+	data := &ietfInterfaces{}
+	filter := `<interfaces xmlns="` + ietfInterfacesNamespace + `"><interface><name/><description/></interface></interfaces>` +
+		`<interfaces-state xmlns="` + ietfInterfacesNamespace + `"/>`
+	err := client.NetconfGet(filter, data)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make(map[string]string)
+	for _, i := range data.Config {
+		descriptions[i.Name] = i.Description
+	}
+
+	items := []Interface{}
+	for _, i := range data.State {
+		items = append(items, Interface{
+			Name:           i.Name,
+			Description:    descriptions[i.Name],
+			MacAddress:     ciscoMacAddress(i.PhysAddress),
+			AdminStatus:    i.AdminStatus,
+			OperStatus:     i.OperStatus,
+			InputBytes:     i.Statistics.InOctets,
+			InputErrors:    i.Statistics.InErrors,
+			InputDrops:     i.Statistics.InDiscards,
+			InputBroadcast: i.Statistics.InBroadcastPkts,
+			InputMulticast: i.Statistics.InMulticastPkts,
+			OutputBytes:    i.Statistics.OutOctets,
+			OutputErrors:   i.Statistics.OutErrors,
+			OutputDrops:    i.Statistics.OutDiscards,
+			Speed:          i.Speed,
+		})
+	}
+
+	return items, nil
+}
+
+// ciscoMacAddress converts a MAC address like 00:11:22:33:44:55 to the format of the CLI (0011.2233.4455)
+func ciscoMacAddress(mac string) string {
+	hex := strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "").Replace(mac))
+	if len(hex) != 12 {
+		return mac
+	}
+
+	return hex[0:4] + "." + hex[4:8] + "." + hex[8:12]
+}
+
+// portSpeed converts an openconfig port speed like oc-eth:SPEED_10GB to bps
+func portSpeed(speed string) float64 {
+	m := portSpeedRegexp.FindStringSubmatch(speed)
+	if m == nil {
+		return 0
+	}
+
+	if m[2] == "GB" {
+		return util.Str2float64(m[1]) * 1000 * 1000 * 1000
+	}
+
+	return util.Str2float64(m[1]) * 1000 * 1000
+}
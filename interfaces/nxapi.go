@@ -0,0 +1,94 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+type nxapiInterfaces struct {
+	Table struct {
+		Rows json.RawMessage `json:"ROW_interface"`
+	} `json:"TABLE_interface"`
+}
+
+type nxapiInterface struct {
+	Name        string `json:"interface"`
+	State       string `json:"state"`
+	AdminState  string `json:"admin_state"`
+	Description string `json:"desc"`
+	MacAddress  string `json:"eth_hw_addr"`
+
+	// bandwidth in kbit/s
+	Bandwidth rpc.JSONFloat `json:"eth_bw"`
+
+	InBytes     rpc.JSONFloat `json:"eth_inbytes"`
+	InErrors    rpc.JSONFloat `json:"eth_inerr"`
+	InDiscards  rpc.JSONFloat `json:"eth_indiscard"`
+	InBroadcast rpc.JSONFloat `json:"eth_inbcast"`
+	InMulticast rpc.JSONFloat `json:"eth_inmcast"`
+	OutBytes    rpc.JSONFloat `json:"eth_outbytes"`
+	OutErrors   rpc.JSONFloat `json:"eth_outerr"`
+	OutDiscards rpc.JSONFloat `json:"eth_outdiscard"`
+
+	// SVIs use different keys for status, address and bandwidth
+	SVIAdminState string        `json:"svi_admin_state"`
+	SVILineProto  string        `json:"svi_line_proto"`
+	SVIMacAddress string        `json:"svi_mac"`
+	SVIBandwidth  rpc.JSONFloat `json:"svi_bw"`
+}
+
+// interfacesNXAPI retrieves the interfaces using the structured output of NX-API
+func (c *interfaceCollector) interfacesNXAPI(client *rpc.Client) ([]Interface, error) {
+	data := &nxapiInterfaces{}
+	err := client.RunCommandJSON("show interface", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return nxapiInterfaceItems(data)
+}
+
+func nxapiInterfaceItems(data *nxapiInterfaces) ([]Interface, error) {
+	rows := []nxapiInterface{}
+	err := rpc.UnmarshalRows(data.Table.Rows, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []Interface{}
+	for _, r := range rows {
+		if r.State == "" {
+			r.State = r.SVILineProto
+		}
+		if r.AdminState == "" {
+			r.AdminState = r.SVIAdminState
+		}
+		if r.MacAddress == "" {
+			r.MacAddress = r.SVIMacAddress
+		}
+		if r.Bandwidth == 0 {
+			r.Bandwidth = r.SVIBandwidth
+		}
+
+		items = append(items, Interface{
+			Name:           r.Name,
+			Description:    r.Description,
+			MacAddress:     r.MacAddress,
+			AdminStatus:    strings.ToLower(r.AdminState),
+			OperStatus:     strings.ToLower(r.State),
+			InputBytes:     float64(r.InBytes),
+			InputErrors:    float64(r.InErrors),
+			InputDrops:     float64(r.InDiscards),
+			InputBroadcast: float64(r.InBroadcast),
+			InputMulticast: float64(r.InMulticast),
+			OutputBytes:    float64(r.OutBytes),
+			OutputErrors:   float64(r.OutErrors),
+			OutputDrops:    float64(r.OutDiscards),
+			Speed:          float64(r.Bandwidth) * 1000,
+		})
+	}
+
+	return items, nil
+}
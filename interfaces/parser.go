@@ -11,7 +11,10 @@ import (
 
 // Parse parses cli output and tries to find interfaces with related stats
 func (c *interfaceCollector) Parse(ostype string, output string) ([]Interface, error) {
-	if ostype != rpc.IOSXE && ostype != rpc.NXOS && ostype != rpc.IOS {
+	if ostype == rpc.ASA {
+		return c.parseASA(output)
+	}
+	if ostype != rpc.IOSXE && ostype != rpc.NXOS && ostype != rpc.IOS && ostype != rpc.IOSXR {
 		return nil, errors.New("'show interface' is not implemented for " + ostype)
 	}
 	items := []Interface{}
@@ -19,13 +22,16 @@ func (c *interfaceCollector) Parse(ostype string, output string) ([]Interface, e
 	newIfRegexp := regexp.MustCompile(`(?:^!?(?: |admin|show|.+#).*$|^$)`)
 	macRegexp := regexp.MustCompile(`^\s+Hardware(?: is|:) .+, address(?: is|:) (.*) \(.*\)$`)
 	deviceNameRegexp := regexp.MustCompile(`^([a-zA-Z0-9\/\.-]+) is.*$`)
-	adminStatusRegexp := regexp.MustCompile(`^.+ is (administratively)?\s*(up|down).*, line protocol is\s+(up|down).*$`)
+	adminStatusRegexp := regexp.MustCompile(`^.+ is (administratively)?\s*(up|down).*, line protocol is\s+(?:administratively\s+)?(up|down).*$`)
 	adminStatusNXOSRegexp := regexp.MustCompile(`^\S+ is (up|down)(?:\s|,)?(\(Administratively down\))?.*$`)
 	descRegexp := regexp.MustCompile(`^\s+Description: (.*)$`)
 	dropsRegexp := regexp.MustCompile(`^\s+Input queue: \d+\/\d+\/(\d+)\/\d+ .+ Total output drops: (\d+)$`)
 	multiBroadNXOS := regexp.MustCompile(`^.* (\d+) multicast packets\s+(\d+) broadcast packets$`)               // NX OS
 	multiBroadIOSXE := regexp.MustCompile(`^\s+Received\s+(\d+)\sbroadcasts \((\d+) (?:IP\s)?multicast(?:s)?\)`) // IOS XE
 	multiBroadIOS := regexp.MustCompile(`^\s*Received (\d+) broadcasts.*$`)                                      // IOS
+	multiBroadIOSXR := regexp.MustCompile(`^\s+Received (\d+) broadcast packets, (\d+) multicast packets$`)      // IOS XR
+	inputDropsIOSXR := regexp.MustCompile(`^\s+\d+ packets input, (\d+) bytes, (\d+) total input drops$`)        // IOS XR
+	outputDropsIOSXR := regexp.MustCompile(`^\s+\d+ packets output, (\d+) bytes, (\d+) total output drops$`)     // IOS XR
 	inputBytesRegexp := regexp.MustCompile(`^\s+\d+ (?:packets input,|input packets)\s+(\d+) bytes.*$`)
 	outputBytesRegexp := regexp.MustCompile(`^\s+\d+ (?:packets output,|output packets)\s+(\d+) bytes.*$`)
 	inputErrorsRegexp := regexp.MustCompile(`^\s+(\d+) input error(?:s,)? .*$`)
@@ -78,6 +84,12 @@ func (c *interfaceCollector) Parse(ostype string, output string) ([]Interface, e
 		} else if matches := dropsRegexp.FindStringSubmatch(line); matches != nil {
 			current.InputDrops = util.Str2float64(matches[1])
 			current.OutputDrops = util.Str2float64(matches[2])
+		} else if matches := inputDropsIOSXR.FindStringSubmatch(line); matches != nil {
+			current.InputBytes = util.Str2float64(matches[1])
+			current.InputDrops = util.Str2float64(matches[2])
+		} else if matches := outputDropsIOSXR.FindStringSubmatch(line); matches != nil {
+			current.OutputBytes = util.Str2float64(matches[1])
+			current.OutputDrops = util.Str2float64(matches[2])
 		} else if matches := inputBytesRegexp.FindStringSubmatch(line); matches != nil {
 			current.InputBytes = util.Str2float64(matches[1])
 		} else if matches := outputBytesRegexp.FindStringSubmatch(line); matches != nil {
@@ -98,6 +110,9 @@ func (c *interfaceCollector) Parse(ostype string, output string) ([]Interface, e
 		} else if matches := multiBroadIOSXE.FindStringSubmatch(line); matches != nil {
 			current.InputBroadcast = util.Str2float64(matches[1])
 			current.InputMulticast = util.Str2float64(matches[2])
+		} else if matches := multiBroadIOSXR.FindStringSubmatch(line); matches != nil {
+			current.InputBroadcast = util.Str2float64(matches[1])
+			current.InputMulticast = util.Str2float64(matches[2])
 		} else if matches := multiBroadIOS.FindStringSubmatch(line); matches != nil {
 			current.InputBroadcast = util.Str2float64(matches[1])
 		}
@@ -105,6 +120,78 @@ func (c *interfaceCollector) Parse(ostype string, output string) ([]Interface, e
 	return append(items, current), nil
 }
 
+// parseASA parses the output of show interface on ASA, which lists the interfaces with their nameif
+// and repeats the counters in the traffic statistics of the nameif
+func (c *interfaceCollector) parseASA(output string) ([]Interface, error) {
+	items := []Interface{}
+	deviceNameRegexp := regexp.MustCompile(`^Interface (\S+) "[^"]*", is (administratively )?(up|down), line protocol is (up|down)`)
+	speedRegexp := regexp.MustCompile(`^\s+Hardware is .+, BW (\d+) Mbps`)
+	descRegexp := regexp.MustCompile(`^\s+Description: (.*)$`)
+	macRegexp := regexp.MustCompile(`^\s+MAC address (\S+), MTU`)
+	inputBytesRegexp := regexp.MustCompile(`^\s+\d+ packets input, (\d+) bytes`)
+	outputBytesRegexp := regexp.MustCompile(`^\s+\d+ packets output, (\d+) bytes`)
+	broadcastRegexp := regexp.MustCompile(`^\s+Received (\d+) broadcasts`)
+	inputErrorsRegexp := regexp.MustCompile(`^\s+(\d+) input errors,`)
+	outputErrorsRegexp := regexp.MustCompile(`^\s+(\d+) output errors,`)
+	dropsRegexp := regexp.MustCompile(`^\s+(\d+) packets dropped$`)
+	trafficStatsRegexp := regexp.MustCompile(`^\s+Traffic Statistics for `)
+
+	var current *Interface
+	trafficStats := false
+	for _, line := range strings.Split(output, "\n") {
+		if matches := deviceNameRegexp.FindStringSubmatch(line); matches != nil {
+			if current != nil {
+				items = append(items, *current)
+			}
+			current = &Interface{
+				Name:        matches[1],
+				AdminStatus: "up",
+				OperStatus:  "down",
+			}
+			if matches[2] != "" {
+				current.AdminStatus = "down"
+			}
+			if matches[3] == "up" && matches[4] == "up" {
+				current.OperStatus = "up"
+			}
+			trafficStats = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		if trafficStatsRegexp.MatchString(line) {
+			trafficStats = true
+		} else if matches := dropsRegexp.FindStringSubmatch(line); matches != nil {
+			current.InputDrops = util.Str2float64(matches[1])
+		} else if trafficStats {
+			continue
+		} else if matches := speedRegexp.FindStringSubmatch(line); matches != nil {
+			current.Speed = util.Str2float64(matches[1]) * 1000000
+		} else if matches := descRegexp.FindStringSubmatch(line); matches != nil {
+			current.Description = matches[1]
+		} else if matches := macRegexp.FindStringSubmatch(line); matches != nil {
+			current.MacAddress = matches[1]
+		} else if matches := inputBytesRegexp.FindStringSubmatch(line); matches != nil {
+			current.InputBytes = util.Str2float64(matches[1])
+		} else if matches := outputBytesRegexp.FindStringSubmatch(line); matches != nil {
+			current.OutputBytes = util.Str2float64(matches[1])
+		} else if matches := broadcastRegexp.FindStringSubmatch(line); matches != nil {
+			current.InputBroadcast = util.Str2float64(matches[1])
+		} else if matches := inputErrorsRegexp.FindStringSubmatch(line); matches != nil {
+			current.InputErrors = util.Str2float64(matches[1])
+		} else if matches := outputErrorsRegexp.FindStringSubmatch(line); matches != nil {
+			current.OutputErrors = util.Str2float64(matches[1])
+		}
+	}
+	if current != nil {
+		items = append(items, *current)
+	}
+
+	return items, nil
+}
+
 // ParseVlans parses cli output and tries to find vlans with related traffic stats
 func (c *interfaceCollector) ParseVlans(ostype string, output string) ([]Interface, error) {
 	if ostype != rpc.IOSXE {
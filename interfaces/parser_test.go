@@ -0,0 +1,54 @@
+package interfaces
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+func TestParseIOSXR(t *testing.T) {
+	output, err := os.ReadFile("../cmd/fakecisco/fixtures/iosxr/show_interface.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &interfaceCollector{}
+	items, err := c.Parse(rpc.IOSXR, string(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"TenGigE0/0/0/0", "HundredGigE0/0/0/1", "TenGigE0/0/0/2", "Bundle-Ether1", "Loopback0"}
+	if len(items) != len(names) {
+		t.Fatalf("got %d interfaces, want %d", len(items), len(names))
+	}
+	for i, name := range names {
+		if items[i].Name != name {
+			t.Errorf("interface %d: got name %q, want %q", i, items[i].Name, name)
+		}
+	}
+
+	want := Interface{
+		Name:           "TenGigE0/0/0/0",
+		MacAddress:     "0011.2233.4400",
+		Description:    "uplink to core1",
+		AdminStatus:    "up",
+		OperStatus:     "up",
+		InputErrors:    3,
+		OutputErrors:   0,
+		InputDrops:     12,
+		OutputDrops:    5,
+		InputBytes:     98765432100,
+		OutputBytes:    87654321000,
+		InputBroadcast: 42,
+		InputMulticast: 1234,
+		Speed:          10000000000,
+	}
+	if items[0] != want {
+		t.Errorf("got %+v, want %+v", items[0], want)
+	}
+
+	if items[2].AdminStatus != "down" || items[2].OperStatus != "down" {
+		t.Errorf("%s: got admin %q oper %q, want down/down", items[2].Name, items[2].AdminStatus, items[2].OperStatus)
+	}
+}
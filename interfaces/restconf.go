@@ -0,0 +1,83 @@
+package interfaces
+
+import (
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const interfacesOperModule = "Cisco-IOS-XE-interfaces-oper"
+
+type restconfInterfaces struct {
+	Interfaces struct {
+		Interfaces []operInterface `json:"interface"`
+	} `json:"Cisco-IOS-XE-interfaces-oper:interfaces"`
+}
+
+// operInterface is an interface of Cisco-IOS-XE-interfaces-oper
+type operInterface struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	AdminStatus string        `json:"admin-status"`
+	OperStatus  string        `json:"oper-status"`
+	PhysAddress string        `json:"phys-address"`
+	Speed       rpc.JSONFloat `json:"speed"`
+	Statistics  struct {
+		InOctets        rpc.JSONFloat `json:"in-octets"`
+		InBroadcastPkts rpc.JSONFloat `json:"in-broadcast-pkts"`
+		InMulticastPkts rpc.JSONFloat `json:"in-multicast-pkts"`
+		InDiscards      rpc.JSONFloat `json:"in-discards"`
+		InErrors        rpc.JSONFloat `json:"in-errors"`
+		OutOctets       rpc.JSONFloat `json:"out-octets"`
+		OutDiscards     rpc.JSONFloat `json:"out-discards"`
+		OutErrors       rpc.JSONFloat `json:"out-errors"`
+	} `json:"statistics"`
+}
+
+// supportsRestconf checks if the interfaces can be retrieved using RESTCONF
+func supportsRestconf(client *rpc.Client) bool {
+	return client.HasRestconfModule(interfacesOperModule)
+}
+
+// interfacesRestconf retrieves the interfaces using Cisco-IOS-XE-interfaces-oper
+func (c *interfaceCollector) interfacesRestconf(client *rpc.Client) ([]Interface, error) {
+	data := &restconfInterfaces{}
+	err := client.RestconfGet(interfacesOperModule+":interfaces", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return operInterfaceItems(data.Interfaces.Interfaces), nil
+}
+
+func operInterfaceItems(interfaces []operInterface) []Interface {
+	items := []Interface{}
+	for _, i := range interfaces {
+		adminStatus := "down"
+		if i.AdminStatus == "if-state-up" {
+			adminStatus = "up"
+		}
+		operStatus := "down"
+		if i.OperStatus == "if-oper-state-ready" {
+			operStatus = "up"
+		}
+
+		s := i.Statistics
+		items = append(items, Interface{
+			Name:           i.Name,
+			Description:    i.Description,
+			MacAddress:     ciscoMacAddress(i.PhysAddress),
+			AdminStatus:    adminStatus,
+			OperStatus:     operStatus,
+			InputBytes:     float64(s.InOctets),
+			InputErrors:    float64(s.InErrors),
+			InputDrops:     float64(s.InDiscards),
+			InputBroadcast: float64(s.InBroadcastPkts),
+			InputMulticast: float64(s.InMulticastPkts),
+			OutputBytes:    float64(s.OutOctets),
+			OutputErrors:   float64(s.OutErrors),
+			OutputDrops:    float64(s.OutDiscards),
+			Speed:          float64(i.Speed),
+		})
+	}
+
+	return items
+}
@@ -0,0 +1,47 @@
+package interfaces
+
+import (
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const (
+	telemetryOperPath  = interfacesOperModule + ":interfaces/interface"
+	telemetryNXAPIPath = "show interface"
+)
+
+// supportsTelemetry checks if the device streams interface data
+func supportsTelemetry(client *rpc.Client) bool {
+	return client.HasTelemetryPath(telemetryOperPath) || client.HasTelemetryPath(telemetryNXAPIPath)
+}
+
+// interfacesTelemetry retrieves the interfaces from the telemetry streamed by the device.
+// IOS XE sends the entries of Cisco-IOS-XE-interfaces-oper, NX-OS the output of show interface (NX-API data source).
+func (c *interfaceCollector) interfacesTelemetry(client *rpc.Client) ([]Interface, error) {
+	if client.HasTelemetryPath(telemetryOperPath) {
+		rows := []operInterface{}
+		err := client.TelemetryRows(telemetryOperPath, &rows)
+		if err != nil {
+			return nil, err
+		}
+
+		return operInterfaceItems(rows), nil
+	}
+
+	rows := []nxapiInterfaces{}
+	err := client.TelemetryRows(telemetryNXAPIPath, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []Interface{}
+	for i := range rows {
+		ifaces, err := nxapiInterfaceItems(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, ifaces...)
+	}
+
+	return items, nil
+}
@@ -85,26 +85,68 @@ func (c *inventoryCollector) Collect(client *rpc.Client, ch chan<- prometheus.Me
 				}
 				return nil
 			}
-			l := append(labelValues, transceiver.Name)
-			l = append(l, transceiver.Description)
-			l = append(l, transceiver.Vendor)
-			l = append(l, transceiver.PartNumber)
-			l = append(l, transceiver.SerialNumber)
-			ch <- prometheus.MustNewConstMetric(transceiverItemDesc, prometheus.GaugeValue, float64(1), l...)
+			c.collectForTransceiver(transceiver, ch, labelValues)
 		}
 
-		for _, inventory_item := range inventory_items {
-			l := append(labelValues, inventory_item.Name)
-			l = append(l, inventory_item.Description)
-			l = append(l, inventory_item.PartNumber)
-			l = append(l, inventory_item.SerialNumber)
-			ch <- prometheus.MustNewConstMetric(inventoryItemDesc, prometheus.GaugeValue, float64(1), l...)
+		c.collectForInventoryItems(inventory_items, ch, labelValues)
 
+	case rpc.IOSXR:
+		interfaces, err := client.GetInterfaceNames(false)
+		if err != nil {
+			if client.Debug {
+				log.Printf("Get interfaces command on %s: %s\n", labelValues[0], err.Error())
+			}
+			return nil
+		}
+		out, err := client.RunCommand("show inventory")
+		if err != nil {
+			if client.Debug {
+				log.Printf("show inventory command on %s: %s\n", labelValues[0], err.Error())
+			}
+			return nil
+		}
+		inventory_items, transceiver_items, err := c.ParseInventory(client.OSType, out, interfaces)
+		if err != nil {
+			if client.Debug {
+				log.Printf("show inventory parsing on %s: %s\n", labelValues[0], err.Error())
+			}
+			return nil
+		}
+
+		// IOS XR has no idprom command, the vendor of transceivers is unknown
+		for _, transceiver_item := range transceiver_items {
+			c.collectForTransceiver(TransceiverItem{
+				Name:         transceiver_item.Name,
+				Description:  transceiver_item.Description,
+				PartNumber:   transceiver_item.PartNumber,
+				SerialNumber: transceiver_item.SerialNumber,
+			}, ch, labelValues)
 		}
 
+		c.collectForInventoryItems(inventory_items, ch, labelValues)
+
 	case rpc.NXOS:
 		return errors.New("inventory collector for NXOS not implemented")
 	}
 
 	return nil
 }
+
+func (c *inventoryCollector) collectForTransceiver(transceiver TransceiverItem, ch chan<- prometheus.Metric, labelValues []string) {
+	l := append(labelValues, transceiver.Name)
+	l = append(l, transceiver.Description)
+	l = append(l, transceiver.Vendor)
+	l = append(l, transceiver.PartNumber)
+	l = append(l, transceiver.SerialNumber)
+	ch <- prometheus.MustNewConstMetric(transceiverItemDesc, prometheus.GaugeValue, float64(1), l...)
+}
+
+func (c *inventoryCollector) collectForInventoryItems(items []InventoryItem, ch chan<- prometheus.Metric, labelValues []string) {
+	for _, inventory_item := range items {
+		l := append(labelValues, inventory_item.Name)
+		l = append(l, inventory_item.Description)
+		l = append(l, inventory_item.PartNumber)
+		l = append(l, inventory_item.SerialNumber)
+		ch <- prometheus.MustNewConstMetric(inventoryItemDesc, prometheus.GaugeValue, float64(1), l...)
+	}
+}
@@ -13,7 +13,7 @@ import (
  * list of transceivers
  */
 func (c *inventoryCollector) ParseInventory(ostype string, output string, interface_names []string) ([]InventoryItem, []InventoryItem, error) {
-	if ostype != rpc.IOSXE && ostype != rpc.IOS {
+	if ostype != rpc.IOSXE && ostype != rpc.IOS && ostype != rpc.IOSXR {
 		return nil, nil, errors.New("'show inventory' is not implemented for " + ostype)
 	}
 	items := []InventoryItem{}
@@ -0,0 +1,47 @@
+package inventory
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+func TestParseInventoryIOSXR(t *testing.T) {
+	output, err := os.ReadFile("../cmd/fakecisco/fixtures/iosxr/show_inventory.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &inventoryCollector{}
+	interfaces := []string{"TenGigE0/0/0/0", "HundredGigE0/0/0/1", "TenGigE0/0/0/2"}
+	items, transceivers, err := c.ParseInventory(rpc.IOSXR, string(output), interfaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantItems := []InventoryItem{
+		{Name: "Rack 0", Description: "ASR-9006 AC Chassis", PartNumber: "ASR-9006-AC-V2", SerialNumber: "FOX1234ABCD"},
+		{Name: "0/RSP0/CPU0", Description: "ASR9K Route Switch Processor with 880G/slot Fabric and 16GB", PartNumber: "A9K-RSP880-SE", SerialNumber: "FOC2345BCDE"},
+		{Name: "0/0/CPU0", Description: "ASR 9000 4-port 100GE + 20-port 10GE Modular Port Adapter", PartNumber: "A9K-MOD400-SE", SerialNumber: "FOC3456CDEF"},
+		{Name: "0/FT0", Description: "ASR-9006 Fan Tray V2", PartNumber: "ASR-9006-FAN-V2", SerialNumber: "FOC4567DEFA"},
+		{Name: "0/PM0", Description: "3kW AC V2 Power Module", PartNumber: "A9K-3KW-AC", SerialNumber: "POG5678EFAB"},
+	}
+	wantTransceivers := []InventoryItem{
+		{Name: "TenGigE0/0/0/0", Description: "Cisco SFP+ 10G LR Pluggable Optics Module", PartNumber: "SFP-10G-LR", SerialNumber: "FNS12345678"},
+		{Name: "HundredGigE0/0/0/1", Description: "Cisco QSFP28 100G LR4 Pluggable Optics Module", PartNumber: "QSFP-100G-LR4-S", SerialNumber: "FNS23456789"},
+	}
+	compareItems(t, "items", items, wantItems)
+	compareItems(t, "transceivers", transceivers, wantTransceivers)
+}
+
+func compareItems(t *testing.T, kind string, got, want []InventoryItem) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d %s, want %d: %+v", len(got), kind, len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %+v, want %+v", got[i], want[i])
+		}
+	}
+}
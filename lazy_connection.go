@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"sync"
+
+	"github.com/lwlcom/cisco_exporter/connector"
+)
+
+// lazyConnection connects to the CLI of a device on the first command. It is used for devices
+// mostly queried using other transports, so the CLI is only opened if a collector falls back to it.
+type lazyConnection struct {
+	host    string
+	connect func() (connector.Connection, func(), error)
+	conn    connector.Connection
+	release func()
+	err     error
+	mu      sync.Mutex
+}
+
+func (c *lazyConnection) connection() (connector.Connection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil && c.err == nil {
+		c.conn, c.release, c.err = c.connect()
+	}
+
+	return c.conn, c.err
+}
+
+// RunCommand runs a command against the device, connecting to it if not done yet
+func (c *lazyConnection) RunCommand(ctx context.Context, cmd string) (string, error) {
+	conn, err := c.connection()
+	if err != nil {
+		return "", err
+	}
+
+	return conn.RunCommand(ctx, cmd)
+}
+
+// RunCommandExpect runs a command against the device, connecting to it if not done yet
+func (c *lazyConnection) RunCommandExpect(ctx context.Context, cmd string, prompt *regexp.Regexp) (string, error) {
+	conn, err := c.connection()
+	if err != nil {
+		return "", err
+	}
+
+	return conn.RunCommandExpect(ctx, cmd, prompt)
+}
+
+// Alive checks if the connection can still be used to run commands
+func (c *lazyConnection) Alive() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err == nil && (c.conn == nil || c.conn.Alive())
+}
+
+func (c *lazyConnection) String() string {
+	return c.host
+}
+
+// Close releases the connection if it was established
+func (c *lazyConnection) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.release != nil {
+		c.release()
+		c.release = nil
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/config"
+	"github.com/lwlcom/cisco_exporter/connector"
+)
+
+// sessionLimiter limits the number of concurrent sessions to devices
+type sessionLimiter struct {
+	name    string
+	slots   chan struct{}
+	waiting int64
+}
+
+func newSessionLimiter(name string, max int) *sessionLimiter {
+	return &sessionLimiter{
+		name:  name,
+		slots: make(chan struct{}, max),
+	}
+}
+
+// acquire waits for a free slot until ctx is done
+func (l *sessionLimiter) acquire(ctx context.Context) error {
+	atomic.AddInt64(&l.waiting, 1)
+	defer atomic.AddInt64(&l.waiting, -1)
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("no free session slot in limit %s: %w", l.name, ctx.Err())
+	}
+}
+
+func (l *sessionLimiter) release() {
+	<-l.slots
+}
+
+func (l *sessionLimiter) queueDepth() int64 {
+	return atomic.LoadInt64(&l.waiting)
+}
+
+type sessionLimiters struct {
+	global  *sessionLimiter
+	groups  map[string]*sessionLimiter
+	maxWait time.Duration
+}
+
+func sessionLimitersForConfig(cfg *config.Config) *sessionLimiters {
+	c := cfg.Concurrency
+	l := &sessionLimiters{
+		groups:  make(map[string]*sessionLimiter),
+		maxWait: time.Duration(c.MaxWait) * time.Second,
+	}
+
+	if c.MaxSessions > 0 {
+		l.global = newSessionLimiter("global", c.MaxSessions)
+	}
+
+	for group, max := range c.Groups {
+		if max > 0 {
+			l.groups[group] = newSessionLimiter("group:"+group, max)
+		}
+	}
+
+	return l
+}
+
+// acquire reserves a session slot for device in its group and the global limit.
+// It returns a function to free the slots again and the time spent waiting.
+func (l *sessionLimiters) acquire(ctx context.Context, device *connector.Device) (func(), time.Duration, error) {
+	t := time.Now()
+
+	if l.maxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.maxWait)
+		defer cancel()
+	}
+
+	// slots are always taken in the same order (group, global) so waiting scrapes can not block each other
+	acquired := make([]*sessionLimiter, 0, 2)
+	releaseAll := func() {
+		for _, s := range acquired {
+			s.release()
+		}
+	}
+
+	for _, s := range []*sessionLimiter{l.groups[device.DeviceConfig.Group], l.global} {
+		if s == nil {
+			continue
+		}
+
+		err := s.acquire(ctx)
+		if err != nil {
+			releaseAll()
+			return nil, time.Since(t), err
+		}
+		acquired = append(acquired, s)
+	}
+
+	return releaseAll, time.Since(t), nil
+}
+
+func (l *sessionLimiters) all() []*sessionLimiter {
+	all := make([]*sessionLimiter, 0, len(l.groups)+1)
+	if l.global != nil {
+		all = append(all, l.global)
+	}
+
+	for _, s := range l.groups {
+		all = append(all, s)
+	}
+
+	return all
+}
@@ -2,13 +2,22 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/lwlcom/cisco_exporter/config"
 	"github.com/lwlcom/cisco_exporter/connector"
+	"github.com/lwlcom/cisco_exporter/rpc"
+	"github.com/lwlcom/cisco_exporter/telemetry"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
@@ -20,12 +29,30 @@ var (
 	showVersion        = flag.Bool("version", false, "Print version information.")
 	listenAddress      = flag.String("web.listen-address", ":9362", "Address on which to expose metrics and web interface.")
 	metricsPath        = flag.String("web.telemetry-path", "/metrics", "Path under which to expose metrics.")
+	timeoutOffset      = flag.Float64("web.timeout-offset", 0.5, "Offset to subtract from the scrape timeout requested by Prometheus in seconds")
 	sshHosts           = flag.String("ssh.targets", "", "SSH Hosts to scrape")
 	sshUsername        = flag.String("ssh.user", "cisco_exporter", "Username to use for SSH connection")
 	sshPassword        = flag.String("ssh.password", "", "Password to use for SSH connection")
 	sshKeyFile         = flag.String("ssh.keyfile", "", "Key file to use for SSH connection")
+	sshKeyPassphrase   = flag.String("ssh.key-passphrase-file", "", "File containing the passphrase of the SSH key file")
+	sshAgent           = flag.Bool("ssh.agent", false, "Use keys of the ssh-agent listening on SSH_AUTH_SOCK")
+	sshEnablePassword  = flag.String("ssh.enable-password", "", "Enable secret to enter privileged exec mode")
+	sshKnownHostsFile  = flag.String("ssh.known-hosts-file", "", "Known hosts file to verify SSH host keys against")
+	sshTrustOnFirstUse = flag.Bool("ssh.trust-on-first-use", false, "Add host keys of unknown hosts to the known hosts file")
+	sshSkipHostKeys    = flag.Bool("ssh.insecure-skip-host-key-verify", false, "Do not verify SSH host keys (insecure)")
 	sshTimeout         = flag.Int("ssh.timeout", 5, "Timeout to use for SSH connection")
+	sshPersistent      = flag.Bool("ssh.persistent-connections", false, "Keep SSH connections open between scrapes")
+	sshIdleTimeout     = flag.Int("ssh.idle-timeout", 300, "Close persistent SSH connections unused for this many seconds")
+	sshMaxSessions     = flag.Int("ssh.max-concurrent-sessions", 0, "Maximum number of concurrent sessions to devices (0 = unlimited)")
+	sshMaxWait         = flag.Int("ssh.max-wait", 10, "Maximum time in seconds to wait for a free session slot")
 	sshBatchSize       = flag.Int("ssh.batch-size", 10000, "The SSH response batch size")
+	replayDirectory    = flag.String("replay.directory", "", "Serve recorded command outputs from this directory instead of connecting to the devices")
+	recordEnabled      = flag.Bool("record.enabled", false, "Record transcripts of the sessions to all devices")
+	recordDirectory    = flag.String("record.directory", "", "Directory to write session transcripts to")
+	recordMaxFiles     = flag.Int("record.max-files", 10, "Number of transcripts to keep per device")
+	pollingEnabled     = flag.Bool("polling.enabled", false, "Poll the devices in the background and answer scrapes with the latest metrics")
+	pollingInterval    = flag.Int("polling.interval", 60, "Interval in seconds each device is polled in")
+	pollingJitter      = flag.Int("polling.jitter", 5, "Maximum random delay in seconds added to the interval")
 	debug              = flag.Bool("debug", false, "Show verbose debug output in log")
 	legacyCiphers      = flag.Bool("legacy.ciphers", false, "Allow legacy CBC ciphers")
 	bgpEnabled         = flag.Bool("bgp.enabled", true, "Scrape bgp metrics")
@@ -36,12 +63,25 @@ var (
 	interfacesEnabled  = flag.Bool("interfaces.enabled", true, "Scrape interface metrics")
 	opticsEnabled      = flag.Bool("optics.enabled", true, "Scrape optic metrics")
 	inventoryEnabled   = flag.Bool("inventory.enabled", false, "Scrape hardware inventory")
+	failoverEnabled    = flag.Bool("failover.enabled", false, "Scrape failover state (ASA)")
+	connectionsEnabled = flag.Bool("connections.enabled", false, "Scrape connection counts and resource usage (ASA)")
+	vpnEnabled         = flag.Bool("vpn.enabled", false, "Scrape VPN session counts (ASA)")
 	configFile         = flag.String("config.file", "", "Path to config file")
 	dynamicIfaceLabels = flag.Bool("dynamic-interface-labels", true, "Parse interface and BGP descriptions to get labels dynamically")
 	descriptionRegex   = flag.String("description-regex", "", "Give a regex to retrieve description labels")
 
-	devices []*connector.Device
-	cfg     *config.Config
+	devices        []*connector.Device
+	cfg            *config.Config
+	connManager    *connector.ConnectionManager
+	limiters       *sessionLimiters
+	recorder       *connector.Recorder
+	telemetryCache *telemetry.Cache
+	telemetryRecv  *telemetry.Receiver
+	gnmiManager    *connector.GNMIManager
+	deviceInfos    *rpc.DeviceInfoCache
+	commandCache   *rpc.CommandCache
+	results        *collectorResults
+	devicePoller   *poller
 )
 
 func init() {
@@ -89,15 +129,99 @@ func initialize() error {
 		return err
 	}
 
+	err = checkHostKeyVerification(c)
+	if err != nil {
+		return err
+	}
+
 	devices, err = devicesForConfig(c)
 	if err != nil {
 		return err
 	}
 	cfg = c
 
+	limiters = sessionLimitersForConfig(cfg)
+
+	if cfg.Recording.Directory != "" {
+		recorder, err = connector.NewRecorder(cfg.Recording.Directory, cfg.Recording.MaxFiles)
+		if err != nil {
+			return err
+		}
+	} else if cfg.Recording.Enabled {
+		return fmt.Errorf("recording requires a transcript directory")
+	}
+
+	if cfg.PersistentConnections {
+		connManager = connector.NewConnectionManager(time.Duration(cfg.IdleTimeout) * time.Second)
+	}
+
+	gnmiManager = connector.NewGNMIManager()
+	deviceInfos = rpc.NewDeviceInfoCache(time.Duration(cfg.DeviceInfoMaxAge) * time.Second)
+	commandCache = rpc.NewCommandCache(commandCacheTTLs(cfg.CommandCache), moduleCommandCacheTTLs(cfg.Modules))
+	results = newCollectorResults()
+
+	if cfg.Polling.Enabled {
+		devicePoller = newPoller(time.Duration(cfg.Polling.Interval)*time.Second, time.Duration(cfg.Polling.Jitter)*time.Second, devices)
+		devicePoller.start(context.Background())
+	}
+
+	if cfg.Telemetry.ListenAddress != "" {
+		telemetryRecv, telemetryCache, err = startTelemetryReceiver(cfg.Telemetry)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// commandCacheTTLs returns the time the output of each command is kept across scrapes
+func commandCacheTTLs(cc *config.CommandCacheConfig) map[string]time.Duration {
+	ttls := make(map[string]time.Duration)
+	for cmd, seconds := range cc.TTL {
+		ttls[strings.TrimSpace(cmd)] = time.Duration(seconds) * time.Second
+	}
+
+	return ttls
+}
+
+// moduleCommandCacheTTLs returns the command cache TTLs of the modules (by name) which configure their own
+func moduleCommandCacheTTLs(modules map[string]*config.ModuleConfig) map[string]map[string]time.Duration {
+	ttls := make(map[string]map[string]time.Duration)
+	for name, m := range modules {
+		if m.CommandCache != nil {
+			ttls[name] = commandCacheTTLs(m.CommandCache)
+		}
+	}
+
+	return ttls
+}
+
+// startTelemetryReceiver accepts telemetry streams of devices in the background
+func startTelemetryReceiver(tc *config.TelemetryConfig) (*telemetry.Receiver, *telemetry.Cache, error) {
+	cache := telemetry.NewCache()
+	r, err := telemetry.NewReceiver(cache, tc.TLSCertFile, tc.TLSKeyFile, cfg.Debug)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l, err := net.Listen("tcp", tc.ListenAddress)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not start telemetry receiver: %w", err)
+	}
+
+	log.Infof("Receiving telemetry on %s\n", l.Addr())
+	go func() {
+		// Serve only returns without error after Stop was called on shutdown
+		err := r.Serve(l)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	return r, cache, nil
+}
+
 func loadConfigFromFlags() *config.Config {
 	c := config.New()
 
@@ -105,10 +229,30 @@ func loadConfigFromFlags() *config.Config {
 	c.LegacyCiphers = *legacyCiphers
 	c.Timeout = *sshTimeout
 	c.BatchSize = *sshBatchSize
+	c.PersistentConnections = *sshPersistent
+	c.IdleTimeout = *sshIdleTimeout
+	c.Concurrency.MaxSessions = *sshMaxSessions
+	c.Concurrency.MaxWait = *sshMaxWait
+	c.Recording.Enabled = *recordEnabled
+	c.Recording.Directory = *recordDirectory
+	c.Recording.MaxFiles = *recordMaxFiles
+	c.Polling.Enabled = *pollingEnabled
+	c.Polling.Interval = *pollingInterval
+	c.Polling.Jitter = *pollingJitter
+	if *replayDirectory != "" {
+		c.Transport = connector.TransportReplay
+		c.ReplayDirectory = *replayDirectory
+	}
 	c.Username = *sshUsername
 	c.Password = *sshPassword
 
 	c.KeyFile = *sshKeyFile
+	c.KeyPassphraseFile = *sshKeyPassphrase
+	c.SSHAgent = *sshAgent
+	c.EnablePassword = *sshEnablePassword
+	c.KnownHostsFile = *sshKnownHostsFile
+	c.TrustOnFirstUse = *sshTrustOnFirstUse
+	c.SkipHostKeyVerify = *sshSkipHostKeys
 	c.IfDescRegStr = *descriptionRegex
 	c.DynamicLabels = *dynamicIfaceLabels
 
@@ -123,6 +267,9 @@ func loadConfigFromFlags() *config.Config {
 	f.Neighbors = neighborsEnabled
 	f.Optics = opticsEnabled
 	f.Inventory = inventoryEnabled
+	f.Failover = failoverEnabled
+	f.Connections = connectionsEnabled
+	f.VPN = vpnEnabled
 
 	return c
 }
@@ -149,8 +296,50 @@ func startServer() {
 	})
 	http.HandleFunc(*metricsPath, handleMetricsRequest)
 
+	srv := &http.Server{Addr: *listenAddress}
+	stopped := make(chan struct{})
+	go func() {
+		shutdownOnSignal(srv)
+		close(stopped)
+	}()
+
 	log.Infof("Listening for %s on %s\n", *metricsPath, *listenAddress)
-	log.Fatal(http.ListenAndServe(*listenAddress, nil))
+	err := srv.ListenAndServe()
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+
+	<-stopped
+}
+
+// shutdownOnSignal stops srv on SIGINT or SIGTERM and closes the persistent connections to the devices, the gNMI
+// subscriptions and the telemetry streams
+func shutdownOnSignal(srv *http.Server) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+
+	log.Infoln("Shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		log.Errorln(err)
+	}
+
+	if connManager != nil {
+		connManager.Close()
+	}
+
+	if gnmiManager != nil {
+		gnmiManager.Close()
+	}
+
+	if telemetryRecv != nil {
+		telemetryRecv.Stop()
+	}
 }
 
 func handleMetricsRequest(w http.ResponseWriter, r *http.Request) {
@@ -163,7 +352,35 @@ func handleMetricsRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c := newCiscoCollector(devs)
+	devs, err = devicesForModule(devs, r.URL.Query().Get("module"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	ctx, cancel, err := contextForRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	defer cancel()
+
+	record, err := recordingForRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	filter, err := collectorsForRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	c := newCiscoCollector(ctx, devs)
+	c.record = record
+	c.filter = filter
+	c.poller = devicePoller
 	reg.MustRegister(c)
 
 	l := log.New()
@@ -174,6 +391,79 @@ func handleMetricsRequest(w http.ResponseWriter, r *http.Request) {
 		ErrorHandling: promhttp.ContinueOnError}).ServeHTTP(w, r)
 }
 
+// contextForRequest returns a context ending at the scrape timeout requested by Prometheus
+func contextForRequest(r *http.Request) (context.Context, context.CancelFunc, error) {
+	v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if v == "" {
+		ctx, cancel := context.WithCancel(r.Context())
+		return ctx, cancel, nil
+	}
+
+	timeout, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse timeout from Prometheus header: %w", err)
+	}
+
+	if *timeoutOffset < timeout {
+		timeout -= *timeoutOffset
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(timeout*float64(time.Second)))
+	return ctx, cancel, nil
+}
+
+// recordingForRequest returns if the request overrides recording of transcripts, nil if it does not
+func recordingForRequest(r *http.Request) (*bool, error) {
+	v := r.URL.Query().Get("record")
+	if v == "" {
+		return nil, nil
+	}
+
+	record, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse record parameter: %w", err)
+	}
+
+	if record && recorder == nil {
+		return nil, fmt.Errorf("recording requires a transcript directory")
+	}
+
+	return &record, nil
+}
+
+// collectorsForRequest returns the collectors selected by the collect[] and exclude[] parameters, nil if all are run
+func collectorsForRequest(r *http.Request) (*collectorFilter, error) {
+	q := r.URL.Query()
+	include, exclude := q["collect[]"], q["exclude[]"]
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	return newCollectorFilter(include, exclude)
+}
+
+// devicesForModule returns copies of devs with the settings of module applied, devs if no module is selected
+func devicesForModule(devs []*connector.Device, module string) ([]*connector.Device, error) {
+	if module == "" {
+		return devs, nil
+	}
+
+	result := make([]*connector.Device, len(devs))
+	for i, d := range devs {
+		dc, err := cfg.DeviceConfigForModule(d.DeviceConfig, module)
+		if err != nil {
+			return nil, err
+		}
+
+		md := *d
+		md.DeviceConfig = dc
+		md.Module = module
+		result[i] = &md
+	}
+
+	return result, nil
+}
+
 func devicesForRequest(r *http.Request) ([]*connector.Device, error) {
 	reqTarget := r.URL.Query().Get("target")
 	if reqTarget == "" {
@@ -201,5 +491,15 @@ func devicesForRequest(r *http.Request) ([]*connector.Device, error) {
 		}
 	}
 
+	if _, found := cfg.Modules[r.URL.Query().Get("module")]; found {
+		// the module provides the settings, everything else is taken from the global config
+		d, err := deviceFromDeviceConfig(&config.DeviceConfig{Host: reqTarget}, reqTarget, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*connector.Device{d}, nil
+	}
+
 	return nil, fmt.Errorf("the target '%s' is not defined in the configuration file", reqTarget)
 }
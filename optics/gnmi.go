@@ -0,0 +1,143 @@
+package optics
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+const openconfigTransceiverModel = "openconfig-platform-transceiver"
+
+type gnmiComponents struct {
+	Components struct {
+		Components []struct {
+			Name  string `json:"name"`
+			State struct {
+				Temperature struct {
+					Instant rpc.JSONFloat `json:"instant"`
+				} `json:"temperature"`
+			} `json:"state"`
+			Transceiver *gnmiTransceiver `json:"transceiver"`
+		} `json:"component"`
+	} `json:"components"`
+}
+
+type gnmiTransceiver struct {
+	State struct {
+		Present       string `json:"present"`
+		SupplyVoltage struct {
+			Instant rpc.JSONFloat `json:"instant"`
+		} `json:"supply-voltage"`
+	} `json:"state"`
+	PhysicalChannels struct {
+		Channels []struct {
+			Index json.Number `json:"index"`
+			State struct {
+				OutputPower struct {
+					Instant rpc.JSONFloat `json:"instant"`
+				} `json:"output-power"`
+				InputPower struct {
+					Instant rpc.JSONFloat `json:"instant"`
+				} `json:"input-power"`
+			} `json:"state"`
+		} `json:"channel"`
+	} `json:"physical-channels"`
+	Thresholds struct {
+		Thresholds []struct {
+			Severity string         `json:"severity"`
+			State    gnmiThresholds `json:"state"`
+		} `json:"threshold"`
+	} `json:"thresholds"`
+}
+
+type gnmiThresholds struct {
+	TempUpper    rpc.JSONFloat `json:"laser-temperature-upper"`
+	TempLower    rpc.JSONFloat `json:"laser-temperature-lower"`
+	VoltageUpper rpc.JSONFloat `json:"supply-voltage-upper"`
+	VoltageLower rpc.JSONFloat `json:"supply-voltage-lower"`
+	TxUpper      rpc.JSONFloat `json:"output-power-upper"`
+	TxLower      rpc.JSONFloat `json:"output-power-lower"`
+	RxUpper      rpc.JSONFloat `json:"input-power-upper"`
+	RxLower      rpc.JSONFloat `json:"input-power-lower"`
+}
+
+// supportsGNMI checks if the transceivers can be retrieved using gNMI
+func supportsGNMI(client *rpc.Client) bool {
+	return client.HasGNMIModel(openconfigTransceiverModel)
+}
+
+// transceiversGNMI retrieves the diagnostics of all transceivers present using openconfig-platform-transceiver.
+// The thresholds of severity CRITICAL are used as alarm, of severity WARNING as warning thresholds.
+func (c *opticsCollector) transceiversGNMI(client *rpc.Client) (map[string]*Optics, error) {
+	data := &gnmiComponents{}
+	err := client.GNMIGet("/components", data)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]*Optics)
+	for _, comp := range data.Components.Components {
+		t := comp.Transceiver
+		if t == nil || identityName(t.State.Present) != "PRESENT" || len(t.PhysicalChannels.Channels) == 0 {
+			continue
+		}
+
+		var alarm, warn gnmiThresholds
+		for _, th := range t.Thresholds.Thresholds {
+			switch identityName(th.Severity) {
+			case "CRITICAL":
+				alarm = th.State
+			case "WARNING":
+				warn = th.State
+			}
+		}
+
+		optics := &Optics{
+			Name:       comp.Name,
+			Lanes:      make(map[string]*Optics),
+			Temp:       float64(comp.State.Temperature.Instant),
+			TempHAT:    float64(alarm.TempUpper),
+			TempHWT:    float64(warn.TempUpper),
+			TempLAT:    float64(alarm.TempLower),
+			TempLWT:    float64(warn.TempLower),
+			Voltage:    float64(t.State.SupplyVoltage.Instant),
+			VoltageHAT: float64(alarm.VoltageUpper),
+			VoltageHWT: float64(warn.VoltageUpper),
+			VoltageLAT: float64(alarm.VoltageLower),
+			VoltageLWT: float64(warn.VoltageLower),
+		}
+
+		channels := t.PhysicalChannels.Channels
+		for _, ch := range channels {
+			lane := optics
+			if len(channels) > 1 {
+				lane = &Optics{
+					Name:  comp.Name,
+					Index: ch.Index.String(),
+				}
+				optics.Lanes[lane.Index] = lane
+			}
+
+			lane.TxPower = float64(ch.State.OutputPower.Instant)
+			lane.TxPowerHAT = float64(alarm.TxUpper)
+			lane.TxPowerHWT = float64(warn.TxUpper)
+			lane.TxPowerLAT = float64(alarm.TxLower)
+			lane.TxPowerLWT = float64(warn.TxLower)
+			lane.RxPower = float64(ch.State.InputPower.Instant)
+			lane.RxPowerHAT = float64(alarm.RxUpper)
+			lane.RxPowerHWT = float64(warn.RxUpper)
+			lane.RxPowerLAT = float64(alarm.RxLower)
+			lane.RxPowerLWT = float64(warn.RxLower)
+		}
+
+		items[comp.Name] = optics
+	}
+
+	return items, nil
+}
+
+// identityName removes the module from an identity like openconfig-alarm-types:CRITICAL
+func identityName(s string) string {
+	return s[strings.LastIndex(s, ":")+1:]
+}
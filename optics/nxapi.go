@@ -0,0 +1,119 @@
+package optics
+
+import (
+	"encoding/json"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+type nxapiTransceivers struct {
+	Table struct {
+		Rows json.RawMessage `json:"ROW_interface"`
+	} `json:"TABLE_interface"`
+}
+
+type nxapiTransceiver struct {
+	Name  string `json:"interface"`
+	SFP   string `json:"sfp"`
+	Lanes struct {
+		Rows json.RawMessage `json:"ROW_lane"`
+	} `json:"TABLE_lane"`
+}
+
+type nxapiLane struct {
+	Number string `json:"lane_number"`
+
+	Temp    rpc.JSONFloat `json:"temperature"`
+	TempHAT rpc.JSONFloat `json:"temp_alrm_hi"`
+	TempLAT rpc.JSONFloat `json:"temp_alrm_lo"`
+	TempHWT rpc.JSONFloat `json:"temp_warn_hi"`
+	TempLWT rpc.JSONFloat `json:"temp_warn_lo"`
+
+	Voltage    rpc.JSONFloat `json:"voltage"`
+	VoltageHAT rpc.JSONFloat `json:"volt_alrm_hi"`
+	VoltageLAT rpc.JSONFloat `json:"volt_alrm_lo"`
+	VoltageHWT rpc.JSONFloat `json:"volt_warn_hi"`
+	VoltageLWT rpc.JSONFloat `json:"volt_warn_lo"`
+
+	TxPower    rpc.JSONFloat `json:"tx_pwr"`
+	TxPowerHAT rpc.JSONFloat `json:"tx_pwr_alrm_hi"`
+	TxPowerLAT rpc.JSONFloat `json:"tx_pwr_alrm_lo"`
+	TxPowerHWT rpc.JSONFloat `json:"tx_pwr_warn_hi"`
+	TxPowerLWT rpc.JSONFloat `json:"tx_pwr_warn_lo"`
+
+	RxPower    rpc.JSONFloat `json:"rx_pwr"`
+	RxPowerHAT rpc.JSONFloat `json:"rx_pwr_alrm_hi"`
+	RxPowerLAT rpc.JSONFloat `json:"rx_pwr_alrm_lo"`
+	RxPowerHWT rpc.JSONFloat `json:"rx_pwr_warn_hi"`
+	RxPowerLWT rpc.JSONFloat `json:"rx_pwr_warn_lo"`
+}
+
+// transceiversNXAPI retrieves the diagnostics of all transceivers using the structured output of NX-API.
+// Temperature and voltage are taken from the first lane, power levels of multi lane transceivers are reported per lane.
+func (c *opticsCollector) transceiversNXAPI(client *rpc.Client) (map[string]*Optics, error) {
+	data := &nxapiTransceivers{}
+	err := client.RunCommandJSON("show interface transceiver details", data)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []nxapiTransceiver{}
+	err = rpc.UnmarshalRows(data.Table.Rows, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]*Optics)
+	for _, r := range rows {
+		lanes := []nxapiLane{}
+		err = rpc.UnmarshalRows(r.Lanes.Rows, &lanes)
+		if err != nil {
+			return nil, err
+		}
+		if r.SFP != "present" || len(lanes) == 0 {
+			continue
+		}
+
+		first := lanes[0]
+		optics := &Optics{
+			Name:       r.Name,
+			Lanes:      make(map[string]*Optics),
+			Temp:       float64(first.Temp),
+			TempHAT:    float64(first.TempHAT),
+			TempHWT:    float64(first.TempHWT),
+			TempLAT:    float64(first.TempLAT),
+			TempLWT:    float64(first.TempLWT),
+			Voltage:    float64(first.Voltage),
+			VoltageHAT: float64(first.VoltageHAT),
+			VoltageHWT: float64(first.VoltageHWT),
+			VoltageLAT: float64(first.VoltageLAT),
+			VoltageLWT: float64(first.VoltageLWT),
+		}
+
+		for _, l := range lanes {
+			lane := optics
+			if len(lanes) > 1 {
+				lane = &Optics{
+					Name:  r.Name,
+					Index: l.Number,
+				}
+				optics.Lanes[l.Number] = lane
+			}
+
+			lane.TxPower = float64(l.TxPower)
+			lane.TxPowerHAT = float64(l.TxPowerHAT)
+			lane.TxPowerHWT = float64(l.TxPowerHWT)
+			lane.TxPowerLAT = float64(l.TxPowerLAT)
+			lane.TxPowerLWT = float64(l.TxPowerLWT)
+			lane.RxPower = float64(l.RxPower)
+			lane.RxPowerHAT = float64(l.RxPowerHAT)
+			lane.RxPowerHWT = float64(l.RxPowerHWT)
+			lane.RxPowerLAT = float64(l.RxPowerLAT)
+			lane.RxPowerLWT = float64(l.RxPowerLWT)
+		}
+
+		items[r.Name] = optics
+	}
+
+	return items, nil
+}
@@ -2,6 +2,7 @@ package optics
 
 import (
 	"log"
+	"regexp"
 
 	"github.com/lwlcom/cisco_exporter/rpc"
 
@@ -65,6 +66,8 @@ func init() {
 	opticsRXLWTDesc = prometheus.NewDesc(prefix+"rx_low_warn_threshold", "Transceiver rx power low warning threshold", l, nil)
 }
 
+var iosxrPortRegexp = regexp.MustCompile(`^[A-Za-z]+(\d+/\d+/\d+/\d+)$`)
+
 type opticsCollector struct {
 }
 
@@ -107,6 +110,17 @@ func (*opticsCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect collects metrics from Cisco
 func (c *opticsCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	if supportsGNMI(client) {
+		optics_data, err := c.transceiversGNMI(client)
+		if err != nil {
+			return err
+		}
+
+		for _, optics := range optics_data {
+			c.collectForOptics(optics, ch, labelValues)
+		}
+		return nil
+	}
 
 	switch client.OSType {
 	case rpc.IOS, rpc.IOSXE:
@@ -125,44 +139,59 @@ func (c *opticsCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metri
 			return nil
 		}
 
-		for i, optics := range optics_data {
-			l := append(labelValues, i)
-			ch <- prometheus.MustNewConstMetric(opticsTempDesc, prometheus.GaugeValue, float64(optics.Temp), l...)
-			ch <- prometheus.MustNewConstMetric(opticsTempHATDesc, prometheus.GaugeValue, float64(optics.TempHAT), l...)
-			ch <- prometheus.MustNewConstMetric(opticsTempHWTDesc, prometheus.GaugeValue, float64(optics.TempHWT), l...)
-			ch <- prometheus.MustNewConstMetric(opticsTempLATDesc, prometheus.GaugeValue, float64(optics.TempLAT), l...)
-			ch <- prometheus.MustNewConstMetric(opticsTempLWTDesc, prometheus.GaugeValue, float64(optics.TempLWT), l...)
-
-			ch <- prometheus.MustNewConstMetric(opticsVoltageDesc, prometheus.GaugeValue, float64(optics.Voltage), l...)
-			ch <- prometheus.MustNewConstMetric(opticsVoltageHATDesc, prometheus.GaugeValue, float64(optics.VoltageHAT), l...)
-			ch <- prometheus.MustNewConstMetric(opticsVoltageHWTDesc, prometheus.GaugeValue, float64(optics.VoltageHWT), l...)
-			ch <- prometheus.MustNewConstMetric(opticsVoltageLATDesc, prometheus.GaugeValue, float64(optics.VoltageLAT), l...)
-			ch <- prometheus.MustNewConstMetric(opticsVoltageLWTDesc, prometheus.GaugeValue, float64(optics.VoltageLWT), l...)
-
-			var data map[string]*Optics
-			if len(optics.Lanes) > 0 {
-				data = optics.Lanes
-			} else {
-				data = map[string]*Optics{"": optics}
+		for _, optics := range optics_data {
+			c.collectForOptics(optics, ch, labelValues)
+		}
+
+	case rpc.IOSXR:
+		interfaces, err := client.GetInterfaceNames(false)
+		if err != nil {
+			if client.Debug {
+				log.Printf("GetInterfaceNames for %s: %s\n", labelValues[0], err.Error())
 			}
+			return nil
+		}
 
-			for _, e := range data {
-				l2 := append(l, e.Index)
-				ch <- prometheus.MustNewConstMetric(opticsTXDesc, prometheus.GaugeValue, float64(e.TxPower), l2...)
-				ch <- prometheus.MustNewConstMetric(opticsTXHATDesc, prometheus.GaugeValue, float64(e.TxPowerHAT), l2...)
-				ch <- prometheus.MustNewConstMetric(opticsTXHWTDesc, prometheus.GaugeValue, float64(e.TxPowerHWT), l2...)
-				ch <- prometheus.MustNewConstMetric(opticsTXLATDesc, prometheus.GaugeValue, float64(e.TxPowerLAT), l2...)
-				ch <- prometheus.MustNewConstMetric(opticsTXLWTDesc, prometheus.GaugeValue, float64(e.TxPowerLWT), l2...)
-
-				ch <- prometheus.MustNewConstMetric(opticsRXDesc, prometheus.GaugeValue, float64(e.RxPower), l2...)
-				ch <- prometheus.MustNewConstMetric(opticsRXHATDesc, prometheus.GaugeValue, float64(e.RxPowerHAT), l2...)
-				ch <- prometheus.MustNewConstMetric(opticsRXHWTDesc, prometheus.GaugeValue, float64(e.RxPowerHWT), l2...)
-				ch <- prometheus.MustNewConstMetric(opticsRXLATDesc, prometheus.GaugeValue, float64(e.RxPowerLAT), l2...)
-				ch <- prometheus.MustNewConstMetric(opticsRXLWTDesc, prometheus.GaugeValue, float64(e.RxPowerLWT), l2...)
+		for _, i := range interfaces {
+			// optics controllers are named like the port of the physical interface (e.g. 0/0/0/0 for TenGigE0/0/0/0)
+			port := iosxrPortRegexp.FindStringSubmatch(i)
+			if port == nil {
+				continue
 			}
+			out, err := client.RunCommand("show controllers optics " + port[1])
+			if err != nil {
+				if client.Debug {
+					log.Printf("Transceiver command on %s: %s\n", labelValues[0], err.Error())
+				}
+				continue
+			}
+			optics, err := c.ParseControllerOptics(client.OSType, i, out)
+			if err != nil {
+				if client.Debug {
+					log.Printf("Transceiver data for %s %s: %s\n", labelValues[0], i, err.Error())
+				}
+				continue
+			}
+
+			c.collectForOptics(optics, ch, labelValues)
 		}
 
 	case rpc.NXOS:
+		if client.NXAPI != nil {
+			optics_data, err := c.transceiversNXAPI(client)
+			if err != nil {
+				if client.Debug {
+					log.Printf("Transceivers for %s: %s\n", labelValues[0], err.Error())
+				}
+				return nil
+			}
+
+			for _, optics := range optics_data {
+				c.collectForOptics(optics, ch, labelValues)
+			}
+			break
+		}
+
 		//iflistcmd := "show interface status | exclude disabled | exclude notconn | exclude sfpAbsent | exclude --------------------------------------------------------------------------------"
 		interfaces, err := client.GetInterfaceNames(false)
 		if err != nil {
@@ -187,7 +216,7 @@ func (c *opticsCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metri
 				}
 				continue
 			}
-			l := append(labelValues, i)
+			l := append(labelValues, i, "")
 
 			ch <- prometheus.MustNewConstMetric(opticsTXDesc, prometheus.GaugeValue, float64(optic.TxPower), l...)
 			ch <- prometheus.MustNewConstMetric(opticsRXDesc, prometheus.GaugeValue, float64(optic.RxPower), l...)
@@ -196,3 +225,40 @@ func (c *opticsCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metri
 
 	return nil
 }
+
+func (c *opticsCollector) collectForOptics(optics *Optics, ch chan<- prometheus.Metric, labelValues []string) {
+	l := append(labelValues, optics.Name)
+	ch <- prometheus.MustNewConstMetric(opticsTempDesc, prometheus.GaugeValue, float64(optics.Temp), l...)
+	ch <- prometheus.MustNewConstMetric(opticsTempHATDesc, prometheus.GaugeValue, float64(optics.TempHAT), l...)
+	ch <- prometheus.MustNewConstMetric(opticsTempHWTDesc, prometheus.GaugeValue, float64(optics.TempHWT), l...)
+	ch <- prometheus.MustNewConstMetric(opticsTempLATDesc, prometheus.GaugeValue, float64(optics.TempLAT), l...)
+	ch <- prometheus.MustNewConstMetric(opticsTempLWTDesc, prometheus.GaugeValue, float64(optics.TempLWT), l...)
+
+	ch <- prometheus.MustNewConstMetric(opticsVoltageDesc, prometheus.GaugeValue, float64(optics.Voltage), l...)
+	ch <- prometheus.MustNewConstMetric(opticsVoltageHATDesc, prometheus.GaugeValue, float64(optics.VoltageHAT), l...)
+	ch <- prometheus.MustNewConstMetric(opticsVoltageHWTDesc, prometheus.GaugeValue, float64(optics.VoltageHWT), l...)
+	ch <- prometheus.MustNewConstMetric(opticsVoltageLATDesc, prometheus.GaugeValue, float64(optics.VoltageLAT), l...)
+	ch <- prometheus.MustNewConstMetric(opticsVoltageLWTDesc, prometheus.GaugeValue, float64(optics.VoltageLWT), l...)
+
+	var data map[string]*Optics
+	if len(optics.Lanes) > 0 {
+		data = optics.Lanes
+	} else {
+		data = map[string]*Optics{"": optics}
+	}
+
+	for _, e := range data {
+		l2 := append(l, e.Index)
+		ch <- prometheus.MustNewConstMetric(opticsTXDesc, prometheus.GaugeValue, float64(e.TxPower), l2...)
+		ch <- prometheus.MustNewConstMetric(opticsTXHATDesc, prometheus.GaugeValue, float64(e.TxPowerHAT), l2...)
+		ch <- prometheus.MustNewConstMetric(opticsTXHWTDesc, prometheus.GaugeValue, float64(e.TxPowerHWT), l2...)
+		ch <- prometheus.MustNewConstMetric(opticsTXLATDesc, prometheus.GaugeValue, float64(e.TxPowerLAT), l2...)
+		ch <- prometheus.MustNewConstMetric(opticsTXLWTDesc, prometheus.GaugeValue, float64(e.TxPowerLWT), l2...)
+
+		ch <- prometheus.MustNewConstMetric(opticsRXDesc, prometheus.GaugeValue, float64(e.RxPower), l2...)
+		ch <- prometheus.MustNewConstMetric(opticsRXHATDesc, prometheus.GaugeValue, float64(e.RxPowerHAT), l2...)
+		ch <- prometheus.MustNewConstMetric(opticsRXHWTDesc, prometheus.GaugeValue, float64(e.RxPowerHWT), l2...)
+		ch <- prometheus.MustNewConstMetric(opticsRXLATDesc, prometheus.GaugeValue, float64(e.RxPowerLAT), l2...)
+		ch <- prometheus.MustNewConstMetric(opticsRXLWTDesc, prometheus.GaugeValue, float64(e.RxPowerLWT), l2...)
+	}
+}
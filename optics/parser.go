@@ -176,3 +176,90 @@ func (c *opticsCollector) ParseTransceiverAll(ostype string, output string) (map
 	}
 	return data, nil
 }
+
+/* ParseControllerOptics parses the output of show controllers optics on IOS XR for the interface name
+ * example (shortened):
+ *
+ *          Laser Bias Current = 32.5 mA
+ *          Actual TX Power = -2.10 dBm
+ *          RX Power = -3.50 dBm
+ *
+ *          Parameter                 High Alarm  Low Alarm  High Warning  Low Warning
+ *          ------------------------  ----------  ---------  ------------  -----------
+ *          Rx Power Threshold(dBm)          2.5      -16.4          -0.5        -12.4
+ *          Tx Power Threshold(dBm)          1.7       -8.2          -1.3         -7.2
+ *          Temp. Threshold(celsius)        75.00     -5.00         70.00         0.00
+ *          Voltage Threshold(volt)          3.63      2.97          3.46         3.13
+ *
+ *          Temperature = 32.00 Celsius
+ *          Voltage = 3.30 V
+ *
+ * Multi lane optics list the power per lane:
+ *
+ *          Lane  Laser Bias    TX Power    RX Power    Output Frequency
+ *          ----  ----------  ----------  ----------  ----------------
+ *            1      43.6 mA    1.53 dBm   -0.21 dBm     230.43 THz
+ */
+func (c *opticsCollector) ParseControllerOptics(ostype string, name string, output string) (*Optics, error) {
+	if ostype != rpc.IOSXR {
+		return nil, errors.New("'show controllers optics' is not implemented for " + ostype)
+	}
+	if strings.Contains(output, "Optics not present") {
+		return nil, errors.New("Transceiver not found")
+	}
+
+	txRegexp := regexp.MustCompile(`^\s+(?:Actual )?TX Power = (?:-?\d+\.\d+ mW \()?(-?\d+\.\d+) dBm`)
+	rxRegexp := regexp.MustCompile(`^\s+RX Power = (?:-?\d+\.\d+ mW \()?(-?\d+\.\d+) dBm`)
+	tempRegexp := regexp.MustCompile(`^\s+Temperature = (-?\d+\.\d+) Celsius`)
+	voltRegexp := regexp.MustCompile(`^\s+Voltage = (-?\d+\.\d+) V`)
+	thresholdRegexp := regexp.MustCompile(`^\s+(Rx Power|Tx Power|Temp\.|Voltage) Threshold\(\S+\)\s+(-?\d+\.\d+)\s+(-?\d+\.\d+)\s+(-?\d+\.\d+)\s+(-?\d+\.\d+)`)
+	laneRegexp := regexp.MustCompile(`^\s+(\d+)\s+-?\d+\.\d+ (?:mA|%)\s+(-?\d+\.\d+) dBm\s+(-?\d+\.\d+) dBm`)
+
+	found := false
+	optics := &Optics{
+		Name:  name,
+		Lanes: make(map[string]*Optics),
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if matches := txRegexp.FindStringSubmatch(line); matches != nil {
+			optics.TxPower = util.Str2float64(matches[1])
+			found = true
+		} else if matches := rxRegexp.FindStringSubmatch(line); matches != nil {
+			optics.RxPower = util.Str2float64(matches[1])
+			found = true
+		} else if matches := tempRegexp.FindStringSubmatch(line); matches != nil {
+			optics.Temp = util.Str2float64(matches[1])
+		} else if matches := voltRegexp.FindStringSubmatch(line); matches != nil {
+			optics.Voltage = util.Str2float64(matches[1])
+		} else if matches := thresholdRegexp.FindStringSubmatch(line); matches != nil {
+			hat, lat, hwt, lwt := util.Str2float64(matches[2]), util.Str2float64(matches[3]), util.Str2float64(matches[4]), util.Str2float64(matches[5])
+			switch matches[1] {
+			case "Rx Power":
+				optics.RxPowerHAT, optics.RxPowerLAT, optics.RxPowerHWT, optics.RxPowerLWT = hat, lat, hwt, lwt
+			case "Tx Power":
+				optics.TxPowerHAT, optics.TxPowerLAT, optics.TxPowerHWT, optics.TxPowerLWT = hat, lat, hwt, lwt
+			case "Temp.":
+				optics.TempHAT, optics.TempLAT, optics.TempHWT, optics.TempLWT = hat, lat, hwt, lwt
+			case "Voltage":
+				optics.VoltageHAT, optics.VoltageLAT, optics.VoltageHWT, optics.VoltageLWT = hat, lat, hwt, lwt
+			}
+		} else if matches := laneRegexp.FindStringSubmatch(line); matches != nil {
+			optics.Lanes[matches[1]] = &Optics{
+				Name:    name,
+				Index:   matches[1],
+				TxPower: util.Str2float64(matches[2]),
+				RxPower: util.Str2float64(matches[3]),
+			}
+		}
+	}
+	if !found {
+		return nil, errors.New("Transceiver not found")
+	}
+
+	// the thresholds are the same for all lanes
+	for _, lane := range optics.Lanes {
+		lane.TxPowerHAT, lane.TxPowerLAT, lane.TxPowerHWT, lane.TxPowerLWT = optics.TxPowerHAT, optics.TxPowerLAT, optics.TxPowerHWT, optics.TxPowerLWT
+		lane.RxPowerHAT, lane.RxPowerLAT, lane.RxPowerHWT, lane.RxPowerLWT = optics.RxPowerHAT, optics.RxPowerLAT, optics.RxPowerHWT, optics.RxPowerLWT
+	}
+	return optics, nil
+}
@@ -0,0 +1,95 @@
+package optics
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+)
+
+func readControllerOptics(t *testing.T, name string) string {
+	t.Helper()
+	output, err := os.ReadFile("../cmd/fakecisco/fixtures/iosxr/show_controllers_optics_" + name + ".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output)
+}
+
+func TestParseControllerOptics(t *testing.T) {
+	c := &opticsCollector{}
+	optics, err := c.ParseControllerOptics(rpc.IOSXR, "TenGigE0/0/0/0", readControllerOptics(t, "0_0_0_0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Optics{
+		Name:       "TenGigE0/0/0/0",
+		Temp:       32,
+		TempHAT:    75,
+		TempLAT:    -5,
+		TempHWT:    70,
+		TempLWT:    0,
+		Voltage:    3.3,
+		VoltageHAT: 3.63,
+		VoltageLAT: 2.97,
+		VoltageHWT: 3.46,
+		VoltageLWT: 3.13,
+		TxPower:    -2.1,
+		TxPowerHAT: 1.7,
+		TxPowerLAT: -8.2,
+		TxPowerHWT: -1.3,
+		TxPowerLWT: -7.2,
+		RxPower:    -3.5,
+		RxPowerHAT: 2.5,
+		RxPowerLAT: -16.4,
+		RxPowerHWT: -0.5,
+		RxPowerLWT: -12.4,
+		Lanes:      map[string]*Optics{},
+	}
+	if !reflect.DeepEqual(*optics, want) {
+		t.Errorf("got %+v, want %+v", *optics, want)
+	}
+}
+
+func TestParseControllerOpticsLanes(t *testing.T) {
+	c := &opticsCollector{}
+	optics, err := c.ParseControllerOptics(rpc.IOSXR, "HundredGigE0/0/0/1", readControllerOptics(t, "0_0_0_1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if optics.TxPower != 7.45 || optics.RxPower != 6.12 || optics.Temp != 38 || optics.Voltage != 3.29 {
+		t.Errorf("got tx %v rx %v temp %v voltage %v, want 7.45 6.12 38 3.29", optics.TxPower, optics.RxPower, optics.Temp, optics.Voltage)
+	}
+
+	want := map[string][2]float64{
+		"1": {1.53, -0.21},
+		"2": {1.38, 0.12},
+		"3": {1.61, -0.45},
+		"4": {1.29, 0.08},
+	}
+	if len(optics.Lanes) != len(want) {
+		t.Fatalf("got %d lanes, want %d", len(optics.Lanes), len(want))
+	}
+	for index, power := range want {
+		lane, found := optics.Lanes[index]
+		if !found {
+			t.Errorf("lane %s missing", index)
+			continue
+		}
+		if lane.Index != index || lane.TxPower != power[0] || lane.RxPower != power[1] {
+			t.Errorf("lane %s: got index %q tx %v rx %v, want tx %v rx %v", index, lane.Index, lane.TxPower, lane.RxPower, power[0], power[1])
+		}
+		if lane.RxPowerHAT != 5.5 || lane.RxPowerLWT != -9.3 || lane.TxPowerHAT != 3.5 || lane.TxPowerLWT != -0.3 {
+			t.Errorf("lane %s: thresholds not copied from the module: %+v", index, lane)
+		}
+	}
+}
+
+func TestParseControllerOpticsNotPresent(t *testing.T) {
+	c := &opticsCollector{}
+	if _, err := c.ParseControllerOptics(rpc.IOSXR, "TenGigE0/0/0/2", readControllerOptics(t, "0_0_0_2")); err == nil {
+		t.Error("expected an error for a port without optics")
+	}
+}
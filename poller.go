@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/connector"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// polledMetrics are the metrics of a device collected by the last poll
+type polledMetrics struct {
+	metrics  []prometheus.Metric
+	polledAt time.Time
+}
+
+// poller collects the metrics of each device on its own schedule in the background and keeps the latest in memory,
+// so scrapes are answered without waiting for the devices. The first poll of each device is delayed by a random part
+// of the interval and each following one by up to jitter, so the devices are polled evenly distributed over time.
+type poller struct {
+	interval time.Duration
+	jitter   time.Duration
+	devices  map[string]*connector.Device
+
+	mu      sync.Mutex
+	rnd     *rand.Rand
+	results map[string]*polledMetrics
+}
+
+func newPoller(interval, jitter time.Duration, devices []*connector.Device) *poller {
+	p := &poller{
+		interval: interval,
+		jitter:   jitter,
+		devices:  make(map[string]*connector.Device, len(devices)),
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		results:  make(map[string]*polledMetrics),
+	}
+	for _, d := range devices {
+		p.devices[d.Host] = d
+	}
+
+	return p
+}
+
+// start polls the devices until ctx is done
+func (p *poller) start(ctx context.Context) {
+	for _, d := range p.devices {
+		go p.run(ctx, d)
+	}
+}
+
+func (p *poller) run(ctx context.Context, device *connector.Device) {
+	next := time.Now().Add(p.random(p.interval))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		// the interval is counted from the start of the poll, so slow devices are polled as often as fast ones
+		next = time.Now().Add(p.interval + p.random(p.jitter))
+		p.poll(ctx, device)
+	}
+}
+
+// poll collects the metrics of device, the poll is canceled if it takes longer than the interval
+func (p *poller) poll(ctx context.Context, device *connector.Device) {
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+
+	t := time.Now()
+	if cfg.Debug {
+		log.Infof("%s: polling device", device.Host)
+	}
+
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	metrics := []prometheus.Metric{}
+	go func() {
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		close(done)
+	}()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	c := newCiscoCollector(ctx, []*connector.Device{device})
+	c.tagMetrics = true
+	c.collectForHost(device, ch, wg)
+	close(ch)
+	<-done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results[device.Host] = &polledMetrics{metrics: metrics, polledAt: t}
+}
+
+func (p *poller) random(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return time.Duration(p.rnd.Int63n(int64(max)))
+}
+
+// polls checks if host is polled in the background
+func (p *poller) polls(host string) bool {
+	_, found := p.devices[host]
+	return found
+}
+
+// result returns the metrics of the last poll of host, nil if it was not polled yet
+func (p *poller) result(host string) *polledMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.results[host]
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/connector"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// collectPolledMetrics returns the metrics collectPolled sends for device by name
+func collectPolledMetrics(t *testing.T, c *ciscoCollector, device *connector.Device) map[string]float64 {
+	t.Helper()
+
+	ch := make(chan prometheus.Metric, 10)
+	c.collectPolled(device, ch)
+	close(ch)
+
+	metrics := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatal(err)
+		}
+
+		var name string
+		switch m.Desc() {
+		case pollPendingDesc:
+			name = "poll_pending"
+		case pollAgeDesc:
+			name = "poll_age_seconds"
+		case upDesc:
+			name = "up"
+		default:
+			t.Fatalf("unexpected metric %s", m.Desc())
+		}
+		metrics[name] = pb.GetGauge().GetValue()
+	}
+
+	return metrics
+}
+
+func TestCollectPolledNotPolledYet(t *testing.T) {
+	device := &connector.Device{Host: "router1"}
+	c := &ciscoCollector{poller: newPoller(time.Minute, 0, []*connector.Device{device})}
+
+	metrics := collectPolledMetrics(t, c, device)
+	if _, found := metrics["up"]; found {
+		t.Error("up must not be sent before the first poll completed")
+	}
+	if metrics["poll_pending"] != 1 {
+		t.Errorf("got poll_pending %v, want 1", metrics["poll_pending"])
+	}
+	if len(metrics) != 1 {
+		t.Errorf("got %v, want only poll_pending", metrics)
+	}
+}
+
+func TestCollectPolled(t *testing.T) {
+	device := &connector.Device{Host: "router1"}
+	p := newPoller(time.Minute, 0, []*connector.Device{device})
+	up := prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, 1, device.Host)
+	p.results[device.Host] = &polledMetrics{metrics: []prometheus.Metric{up}, polledAt: time.Now().Add(-5 * time.Second)}
+	c := &ciscoCollector{poller: p}
+
+	metrics := collectPolledMetrics(t, c, device)
+	if metrics["up"] != 1 {
+		t.Errorf("got up %v, want the polled 1", metrics["up"])
+	}
+	if metrics["poll_pending"] != 0 {
+		t.Errorf("got poll_pending %v, want 0", metrics["poll_pending"])
+	}
+	if age := metrics["poll_age_seconds"]; age < 5 || age > 10 {
+		t.Errorf("got poll_age_seconds %v, want about 5", age)
+	}
+}
+
+func TestPollerPolls(t *testing.T) {
+	p := newPoller(time.Minute, 0, []*connector.Device{{Host: "router1"}, {Host: "router2"}})
+
+	for host, want := range map[string]bool{"router1": true, "router2": true, "router3": false} {
+		if got := p.polls(host); got != want {
+			t.Errorf("polls(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// asaContextRegexp matches the contexts listed by show context, the current one is marked with an asterisk
+var asaContextRegexp = regexp.MustCompile(`^[* ](\S+)\s+\S+\s+`)
+
+// asaContextPrompt returns a regex matching the prompt of the security context name (e.g. host/ctx1# or host/ctx1/act#),
+// or an error of changeto followed by the prompt of the current context
+func asaContextPrompt(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:(?:^|\n)[^\s/#>]+(?:/[^\s/#>]+)*/` + regexp.QuoteMeta(name) + `(?:/[^\s/#>]+)*|ERROR:[^\n]*\n(?:.*\n)*[^\n]*)[#>]\s?$`)
+}
+
+// ASAContexts returns the names of the security contexts of an ASA running in multiple context mode, nil in single context mode
+func (c *Client) ASAContexts() ([]string, error) {
+	out, err := c.RunCommand("show mode")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(out, "mode: multiple") {
+		return nil, nil
+	}
+
+	out, err = c.RunCommand("show context")
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		matches := asaContextRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		contexts = append(contexts, matches[1])
+	}
+
+	return contexts, nil
+}
+
+// ForEachASAContext calls f in every security context of an ASA with the name of the context ("" in single context mode).
+// In multiple context mode it changes to each context using changeto context and returns to the system context afterwards.
+func (c *Client) ForEachASAContext(f func(context string) error) error {
+	contexts, err := c.ASAContexts()
+	if err != nil {
+		return err
+	}
+	if contexts == nil {
+		return f("")
+	}
+
+	defer func() {
+		c.RunCommand("changeto system")
+		c.asaContext = ""
+	}()
+
+	for _, name := range contexts {
+		// wait for the prompt of the context, so no command runs in the previous one
+		out, err := c.RunCommandExpect("changeto context "+name, asaContextPrompt(name))
+		if err != nil {
+			return err
+		}
+		if i := strings.Index(out, "ERROR:"); i >= 0 {
+			return fmt.Errorf("could not change to context %s: %s", name, strings.TrimSpace(strings.SplitN(out[i:], "\n", 2)[0]))
+		}
+		c.asaContext = name
+
+		err = f(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
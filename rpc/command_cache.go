@@ -0,0 +1,161 @@
+package rpc
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// CommandCache keeps the output of slow-changing commands (e.g. show inventory) across scrapes for a configured time.
+// It also counts how many commands of the clients were answered from a cache (hits) or run on the device (misses).
+type CommandCache struct {
+	mu         sync.Mutex
+	ttls       map[string]time.Duration
+	moduleTTLs map[string]map[string]time.Duration
+	entries    map[string]map[string]commandCacheEntry
+	stats      map[string]*CommandCacheStats
+}
+
+type commandCacheEntry struct {
+	output   string
+	cachedAt time.Time
+}
+
+// CommandCacheStats are the number of commands answered from a cache and run on a device
+type CommandCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// NewCommandCache creates a cache keeping the output of the commands in ttls for the time given. moduleTTLs are the
+// TTLs of devices scraped with a module (by name), commands not listed there use ttls.
+func NewCommandCache(ttls map[string]time.Duration, moduleTTLs map[string]map[string]time.Duration) *CommandCache {
+	return &CommandCache{
+		ttls:       ttls,
+		moduleTTLs: moduleTTLs,
+		entries:    make(map[string]map[string]commandCacheEntry),
+		stats:      make(map[string]*CommandCacheStats),
+	}
+}
+
+// ttl returns the time the output of cmd is kept for devices scraped with module, false if it is not cached
+func (c *CommandCache) ttl(module, cmd string) (time.Duration, bool) {
+	if ttl, found := c.moduleTTLs[module][cmd]; found {
+		return ttl, true
+	}
+
+	ttl, found := c.ttls[cmd]
+	return ttl, found
+}
+
+// get returns the output of cmd on host cached under key, if it is not older than the TTL of cmd for module
+func (c *CommandCache) get(host, module, key, cmd string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl, found := c.ttl(module, cmd)
+	if !found {
+		return "", false
+	}
+
+	e, found := c.entries[host][key]
+	if !found || time.Since(e.cachedAt) > ttl {
+		return "", false
+	}
+
+	return e.output, true
+}
+
+// set stores the output of cmd on host under key if a TTL is configured for cmd for module
+func (c *CommandCache) set(host, module, key, cmd, output string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.ttl(module, cmd); !found {
+		return
+	}
+
+	if c.entries[host] == nil {
+		c.entries[host] = make(map[string]commandCacheEntry)
+	}
+	c.entries[host][key] = commandCacheEntry{output: output, cachedAt: time.Now()}
+}
+
+func (c *CommandCache) count(host string, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, found := c.stats[host]
+	if !found {
+		s = &CommandCacheStats{}
+		c.stats[host] = s
+	}
+
+	if hit {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+}
+
+// Stats returns the number of hits and misses for host
+func (c *CommandCache) Stats(host string) CommandCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s, found := c.stats[host]; found {
+		return *s
+	}
+
+	return CommandCacheStats{}
+}
+
+// Invalidate removes the outputs cached for host, e.g. because it might have been reloaded
+func (c *CommandCache) Invalidate(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, host)
+}
+
+// commandCacheKey returns the key the output of cmd is cached under, "" if it must not be cached.
+// Only show commands are cached. On ASAs in multiple context mode the output depends on the current context, outputs
+// of scrapes with a module are kept apart from the ones without.
+func (c *Client) commandCacheKey(cmd string) string {
+	cmd = strings.TrimSpace(cmd)
+	if !strings.HasPrefix(cmd, "show ") {
+		return ""
+	}
+
+	key := cmd
+	if c.asaContext != "" {
+		key = c.asaContext + "/" + key
+	}
+	if c.Module != "" {
+		key = c.Module + ":" + key
+	}
+
+	return key
+}
+
+// cachedOutput returns the output of cmd if it was run in this scrape or is kept in the command cache
+func (c *Client) cachedOutput(key, cmd string) (string, bool) {
+	out, found := c.outputs[key]
+	if !found && c.CommandCache != nil {
+		out, found = c.CommandCache.get(c.Host, c.Module, key, strings.TrimSpace(cmd))
+	}
+
+	if c.CommandCache != nil {
+		c.CommandCache.count(c.Host, found)
+	}
+
+	return out, found
+}
+
+func (c *Client) storeOutput(key, cmd, output string) {
+	c.outputs[key] = output
+
+	if c.CommandCache != nil {
+		c.CommandCache.set(c.Host, c.Module, key, strings.TrimSpace(cmd), output)
+	}
+}
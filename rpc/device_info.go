@@ -0,0 +1,221 @@
+package rpc
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/util"
+)
+
+// DeviceInfo describes a device as far as it was identified. Devices identified by the models they support
+// (NETCONF, RESTCONF, telemetry, gNMI) only have the OS type set.
+type DeviceInfo struct {
+	OSType         string
+	Hostname       string
+	Platform       string
+	Serial         string
+	Version        string
+	Image          string
+	ReloadReason   string
+	ConfigRegister string
+
+	// Uptime is the uptime of the device at IdentifiedAt, 0 if unknown
+	Uptime       time.Duration
+	IdentifiedAt time.Time
+}
+
+// UptimeSeconds returns the current uptime of the device, 0 if unknown
+func (i *DeviceInfo) UptimeSeconds() float64 {
+	if i.Uptime == 0 {
+		return 0
+	}
+
+	return (i.Uptime + time.Since(i.IdentifiedAt)).Seconds()
+}
+
+// HasDetails returns whether anything beyond the OS type was identified
+func (i *DeviceInfo) HasDetails() bool {
+	return i.Hostname != "" || i.Platform != "" || i.Serial != "" || i.Version != "" || i.Image != "" ||
+		i.ReloadReason != "" || i.ConfigRegister != "" || i.Uptime > 0
+}
+
+type versionRegexps struct {
+	version        *regexp.Regexp
+	hostname       *regexp.Regexp
+	platform       *regexp.Regexp
+	serial         *regexp.Regexp
+	image          *regexp.Regexp
+	uptime         *regexp.Regexp
+	reloadReason   []*regexp.Regexp
+	configRegister *regexp.Regexp
+}
+
+var (
+	iosVersionRegexps = versionRegexps{
+		hostname:       regexp.MustCompile(`(?m)^(\S+) uptime is .+$`),
+		platform:       regexp.MustCompile(`(?m)^[Cc]isco (\S+) \(.+\) processor`),
+		serial:         regexp.MustCompile(`(?m)^Processor board ID (\S+)`),
+		image:          regexp.MustCompile(`(?m)^System image file is "([^"]+)"`),
+		uptime:         regexp.MustCompile(`(?m)^\S+ uptime is (.+)$`),
+		reloadReason:   []*regexp.Regexp{regexp.MustCompile(`(?m)^Last reload reason: (.+)$`), regexp.MustCompile(`(?m)^System returned to ROM by (.+)$`)},
+		configRegister: regexp.MustCompile(`(?m)^Configuration register is (\S+)`),
+	}
+	nxosVersionRegexps = versionRegexps{
+		version:      regexp.MustCompile(`(?m)^\s+NXOS: version (.*)$`),
+		hostname:     regexp.MustCompile(`(?m)^\s+Device name: (\S+)`),
+		platform:     regexp.MustCompile(`(?m)^\s+cisco Nexus\S*\s+(\S+) .*chassis`),
+		serial:       regexp.MustCompile(`(?m)^\s+Processor [Bb]oard ID (\S+)`),
+		image:        regexp.MustCompile(`(?m)^\s+NXOS image file is: (\S+)`),
+		uptime:       regexp.MustCompile(`(?m)^Kernel uptime is (.+)$`),
+		reloadReason: []*regexp.Regexp{regexp.MustCompile(`(?m)^\s+Reason: (.+)$`)},
+	}
+	iosxrVersionRegexps = versionRegexps{
+		version:        regexp.MustCompile(`(?m)^Cisco IOS XR Software, Version ([^\s\[]+)`),
+		hostname:       regexp.MustCompile(`(?m)^(\S+) uptime is .+$`),
+		platform:       regexp.MustCompile(`(?m)^cisco (\S+)(?: Series)? \(.*\) processor`),
+		image:          regexp.MustCompile(`(?m)^System image file is "([^"]+)"`),
+		uptime:         regexp.MustCompile(`(?m)^\S+ uptime is (.+)$`),
+		configRegister: regexp.MustCompile(`(?m)^Configuration register on node \S+ is (\S+)`),
+	}
+	asaVersionRegexps = versionRegexps{
+		version:        regexp.MustCompile(`(?m)^Cisco Adaptive Security Appliance Software Version (\S+)`),
+		hostname:       regexp.MustCompile(`(?m)^(\S+) up \d+.+$`),
+		platform:       regexp.MustCompile(`(?m)^Hardware:\s+([^,]+),`),
+		serial:         regexp.MustCompile(`(?m)^Serial Number: (\S+)`),
+		image:          regexp.MustCompile(`(?m)^System image file is "([^"]+)"`),
+		uptime:         regexp.MustCompile(`(?m)^\S+ up (\d+.+)$`),
+		configRegister: regexp.MustCompile(`(?m)^Configuration register is (\S+)`),
+	}
+	ftdVersionRegexps = versionRegexps{
+		version:  regexp.MustCompile(`(?m)^Model\s+: .+ Threat Defense \(\d+\) Version (\S+)`),
+		hostname: regexp.MustCompile(`(?m)^-+\[ (\S+) \]-+`),
+		platform: regexp.MustCompile(`(?m)^Model\s+: Cisco (.+) Threat Defense`),
+	}
+	iosxeVersionRegexp = regexp.MustCompile(`(?m)^Cisco IOS.+XE Software.*, Version ([^\s]+).*$`)
+	iosVersionRegexp   = regexp.MustCompile(`(?m)^.*, Version (.+),.*$`)
+	uptimeRegexp       = regexp.MustCompile(`(\d+) (year|week|day|hour|minute|second)`)
+)
+
+var uptimeUnits = map[string]time.Duration{
+	"year":   365 * 24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+	"day":    24 * time.Hour,
+	"hour":   time.Hour,
+	"minute": time.Minute,
+	"second": time.Second,
+}
+
+// ParseDeviceInfo extracts the device information from the output of show version of a device running ostype
+func ParseDeviceInfo(ostype, output string) *DeviceInfo {
+	re := iosVersionRegexps
+	switch ostype {
+	case IOSXE:
+		re.version = iosxeVersionRegexp
+	case IOS:
+		re.version = iosVersionRegexp
+	case NXOS:
+		re = nxosVersionRegexps
+	case IOSXR:
+		re = iosxrVersionRegexps
+	case ASA:
+		re = asaVersionRegexps
+		if strings.Contains(output, "Threat Defense") {
+			re = ftdVersionRegexps
+		}
+	}
+
+	info := &DeviceInfo{
+		OSType:         ostype,
+		Hostname:       firstMatch(re.hostname, output),
+		Platform:       firstMatch(re.platform, output),
+		Serial:         firstMatch(re.serial, output),
+		Version:        firstMatch(re.version, output),
+		Image:          firstMatch(re.image, output),
+		ConfigRegister: firstMatch(re.configRegister, output),
+		Uptime:         parseUptime(firstMatch(re.uptime, output)),
+		IdentifiedAt:   time.Now(),
+	}
+
+	if ostype == IOSXR && info.Hostname == "System" {
+		// 64-bit IOS XR prints "System uptime is" instead of the hostname
+		info.Hostname = ""
+	}
+
+	for _, r := range re.reloadReason {
+		info.ReloadReason = firstMatch(r, output)
+		if info.ReloadReason != "" {
+			break
+		}
+	}
+
+	return info
+}
+
+func firstMatch(re *regexp.Regexp, output string) string {
+	if re == nil {
+		return ""
+	}
+
+	m := re.FindStringSubmatch(output)
+	if m == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(m[1])
+}
+
+// parseUptime parses an uptime like 1 year, 2 weeks, 3 hours, 7 minutes or 120 day(s), 3 hour(s)
+func parseUptime(s string) time.Duration {
+	var d time.Duration
+	for _, m := range uptimeRegexp.FindAllStringSubmatch(s, -1) {
+		d += time.Duration(util.Str2float64(m[1])) * uptimeUnits[m[2]]
+	}
+
+	return d
+}
+
+// DeviceInfoCache keeps the information of identified devices, so they are not identified on every scrape
+type DeviceInfoCache struct {
+	mu     sync.Mutex
+	maxAge time.Duration
+	infos  map[string]*DeviceInfo
+}
+
+// NewDeviceInfoCache creates a cache returning information for maxAge after identification
+func NewDeviceInfoCache(maxAge time.Duration) *DeviceInfoCache {
+	return &DeviceInfoCache{
+		maxAge: maxAge,
+		infos:  make(map[string]*DeviceInfo),
+	}
+}
+
+// Get returns the information of host, nil if it was not identified within max age
+func (c *DeviceInfoCache) Get(host string) *DeviceInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, found := c.infos[host]
+	if !found || time.Since(info.IdentifiedAt) > c.maxAge {
+		return nil
+	}
+
+	return info
+}
+
+// Set stores the information of host
+func (c *DeviceInfoCache) Set(host string, info *DeviceInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.infos[host] = info
+}
+
+// Invalidate removes the information of host, e.g. because it might have been reloaded
+func (c *DeviceInfoCache) Invalidate(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.infos, host)
+}
@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, dir, name string) string {
+	t.Helper()
+	output, err := os.ReadFile("../cmd/fakecisco/fixtures/" + dir + "/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output)
+}
+
+const day = 24 * time.Hour
+
+func TestParseDeviceInfo(t *testing.T) {
+	tests := []struct {
+		dir    string
+		ostype string
+		want   DeviceInfo
+	}{
+		{
+			dir:    "ios",
+			ostype: IOS,
+			want: DeviceInfo{
+				OSType:         IOS,
+				Hostname:       "switch1",
+				Platform:       "WS-C2960X-48TS-L",
+				Serial:         "FOC1234X0AB",
+				Version:        "15.2(7)E4",
+				Image:          "flash:c2960x-universalk9-mz.152-7.E4/c2960x-universalk9-mz.152-7.E4.bin",
+				ReloadReason:   "power-on",
+				ConfigRegister: "0xF",
+				Uptime:         87*day + 4*time.Hour + 12*time.Minute,
+			},
+		},
+		{
+			dir:    "iosxe",
+			ostype: IOSXE,
+			want: DeviceInfo{
+				OSType:         IOSXE,
+				Hostname:       "switch1",
+				Platform:       "C9500-48Y4C",
+				Serial:         "FCW1234A0BC",
+				Version:        "17.03.04a",
+				Image:          "flash:packages.conf",
+				ReloadReason:   "Reload Command",
+				ConfigRegister: "0x102",
+				Uptime:         380*day + 3*time.Hour + 7*time.Minute,
+			},
+		},
+		{
+			dir:    "iosxr",
+			ostype: IOSXR,
+			want: DeviceInfo{
+				OSType:         IOSXR,
+				Hostname:       "switch1",
+				Platform:       "ASR9K",
+				Version:        "6.5.3",
+				Image:          "disk0:asr9k-os-mbi-6.5.3/0x100305/mbiasr9k-rsp3.vm",
+				ConfigRegister: "0x1922",
+				Uptime:         87*day + 4*time.Hour + 5*time.Minute,
+			},
+		},
+		{
+			dir:    "nxos",
+			ostype: NXOS,
+			want: DeviceInfo{
+				OSType:       NXOS,
+				Hostname:     "switch1",
+				Platform:     "C93180YC-EX",
+				Serial:       "FDO21120U8N",
+				Version:      "9.3(8)",
+				Image:        "bootflash:///nxos.9.3.8.bin",
+				ReloadReason: "Reset Requested by CLI command reload",
+				Uptime:       120*day + 3*time.Hour + 12*time.Minute + 5*time.Second,
+			},
+		},
+		{
+			dir:    "asa",
+			ostype: ASA,
+			want: DeviceInfo{
+				OSType:         ASA,
+				Hostname:       "switch1",
+				Platform:       "ASA5525",
+				Serial:         "FCH1234A5BC",
+				Version:        "9.16(3)19",
+				Image:          "disk0:/asa9-16-3-19-smp-k8.bin",
+				ConfigRegister: "0x1",
+				Uptime:         45*day + 3*time.Hour,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.dir, func(t *testing.T) {
+			info := ParseDeviceInfo(test.ostype, readFixture(t, test.dir, "show_version.txt"))
+			if info.IdentifiedAt.IsZero() {
+				t.Error("IdentifiedAt not set")
+			}
+
+			info.IdentifiedAt = time.Time{}
+			if *info != test.want {
+				t.Errorf("got %+v, want %+v", *info, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDeviceInfoIOSXR64Bit(t *testing.T) {
+	output := "Cisco IOS XR Software, Version 7.3.2\n" +
+		"Copyright (c) 2013-2021 by Cisco Systems, Inc.\n\n" +
+		"System uptime is 3 days 2 hours 1 minute\n"
+
+	info := ParseDeviceInfo(IOSXR, output)
+	if info.Hostname != "" {
+		t.Errorf("got hostname %q, want none", info.Hostname)
+	}
+	if info.Version != "7.3.2" {
+		t.Errorf("got version %q, want 7.3.2", info.Version)
+	}
+	if want := 3*day + 2*time.Hour + time.Minute; info.Uptime != want {
+		t.Errorf("got uptime %s, want %s", info.Uptime, want)
+	}
+}
+
+func TestParseDeviceInfoFTD(t *testing.T) {
+	output := "-------------------[ fw1 ]--------------------\n" +
+		"Model                     : Cisco Firepower 2110 Threat Defense (77) Version 7.0.4 (Build 55)\n" +
+		"UUID                      : 1a2b3c4d-0000-1111-2222-333344445555\n"
+
+	info := ParseDeviceInfo(ASA, output)
+	if info.Hostname != "fw1" || info.Platform != "Firepower 2110" || info.Version != "7.0.4" {
+		t.Errorf("got hostname %q platform %q version %q, want fw1, Firepower 2110 and 7.0.4", info.Hostname, info.Platform, info.Version)
+	}
+}
+
+func TestParseUptime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{input: "", want: 0},
+		{input: "5 minutes", want: 5 * time.Minute},
+		{input: "1 year, 2 weeks, 1 day, 3 hours, 7 minutes", want: 380*day + 3*time.Hour + 7*time.Minute},
+		{input: "120 day(s), 3 hour(s), 12 minute(s), 5 second(s)", want: 120*day + 3*time.Hour + 12*time.Minute + 5*time.Second},
+		{input: "45 days 3 hours", want: 45*day + 3*time.Hour},
+	}
+
+	for _, test := range tests {
+		if got := parseUptime(test.input); got != test.want {
+			t.Errorf("parseUptime(%q) = %s, want %s", test.input, got, test.want)
+		}
+	}
+}
+
+func TestDeviceInfoHasDetails(t *testing.T) {
+	if (&DeviceInfo{OSType: IOSXE, IdentifiedAt: time.Now()}).HasDetails() {
+		t.Error("device identified by its OS only reported details")
+	}
+	if !(&DeviceInfo{OSType: IOSXE, Hostname: "switch1"}).HasDetails() {
+		t.Error("device with hostname reported no details")
+	}
+}
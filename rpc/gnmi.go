@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+)
+
+// HasGNMIModel checks if the device supports gNMI and the YANG model
+func (c *Client) HasGNMIModel(model string) bool {
+	return c.GNMI != nil && c.GNMI.HasModel(model)
+}
+
+// GNMIGet retrieves the data at path (e.g. /interfaces) using gNMI and decodes it into v.
+// The root element of v is the root of the models, so its fields are named like the top level containers (e.g. interfaces).
+// Module names are removed from all member names.
+func (c *Client) GNMIGet(path string, v interface{}) error {
+	if c.GNMI == nil {
+		return errors.New("gNMI is not enabled for the device")
+	}
+	if c.Debug {
+		log.Printf("Running gNMI get on %s: %s\n", c.GNMI, path)
+	}
+
+	data, err := c.GNMI.Get(c.ctx, path)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// identifyGNMI tries to identify the OS by the native models supported by the gNMI server
+func (c *Client) identifyGNMI() bool {
+	if c.GNMI == nil {
+		return false
+	}
+
+	for _, m := range c.GNMI.Models() {
+		switch {
+		case strings.HasPrefix(m, "Cisco-IOS-XE-"):
+			c.OSType = IOSXE
+		case strings.HasPrefix(m, "Cisco-IOS-XR-"):
+			c.OSType = IOSXR
+		case strings.HasPrefix(m, "Cisco-NX-OS-"):
+			c.OSType = NXOS
+		default:
+			continue
+		}
+
+		if c.Debug {
+			log.Printf("Host %s identified as: %s\n", c.GNMI, c.OSType)
+		}
+		return true
+	}
+
+	return false
+}
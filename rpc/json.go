@@ -0,0 +1,28 @@
+package rpc
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// JSONFloat is a number in JSON output. NX-API encodes most numbers as strings, RESTCONF 64 bit values.
+// Values not being a number (e.g. N/A) are decoded as NaN.
+type JSONFloat float64
+
+// UnmarshalJSON decodes numbers and strings containing a number
+func (f *JSONFloat) UnmarshalJSON(b []byte) error {
+	s := strings.TrimSpace(strings.Trim(string(b), `"`))
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		v = math.NaN()
+	}
+	*f = JSONFloat(v)
+
+	return nil
+}
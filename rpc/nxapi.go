@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+)
+
+// RunCommandJSON runs a show command using NX-API and decodes its structured output into v
+func (c *Client) RunCommandJSON(cmd string, v interface{}) error {
+	if c.NXAPI == nil {
+		return errors.New("NX-API is not enabled for the device")
+	}
+	if c.Debug {
+		log.Printf("Running NX-API command on %s: %s\n", c.NXAPI, cmd)
+	}
+
+	body, err := c.NXAPI.Show(c.ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// UnmarshalRows decodes the ROW_ element of a NX-API table into the slice v.
+// NX-API returns a single row as object instead of a list with one element.
+func UnmarshalRows(data json.RawMessage, v interface{}) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+
+	if data[0] == '{' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+
+	return json.Unmarshal(data, v)
+}
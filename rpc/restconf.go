@@ -0,0 +1,30 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+)
+
+// HasRestconfModule checks if the device supports RESTCONF and the YANG module
+func (c *Client) HasRestconfModule(module string) bool {
+	return c.Restconf != nil && c.Restconf.HasModule(module)
+}
+
+// RestconfGet retrieves the data resource at path using RESTCONF and decodes it into v.
+// The root element of v is the resource, so its fields are named like the module and container (e.g. Cisco-IOS-XE-memory-oper:memory-statistics).
+func (c *Client) RestconfGet(path string, v interface{}) error {
+	if c.Restconf == nil {
+		return errors.New("RESTCONF is not enabled for the device")
+	}
+	if c.Debug {
+		log.Printf("Running RESTCONF get on %s: %s\n", c.Restconf, path)
+	}
+
+	b, err := c.Restconf.Get(c.ctx, path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
@@ -1,44 +1,118 @@
 package rpc
 
 import (
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"log"
 
 	"github.com/lwlcom/cisco_exporter/connector"
+	"github.com/lwlcom/cisco_exporter/telemetry"
 )
 
 const (
 	IOSXE string = "IOSXE"
 	NXOS  string = "NXOS"
 	IOS   string = "IOS"
+	IOSXR string = "IOSXR"
+	ASA   string = "ASA"
+)
+
+const (
+	iosxeNativeCapability = "http://cisco.com/ns/yang/Cisco-IOS-XE-native"
+	nxosDeviceCapability  = "http://cisco.com/ns/yang/cisco-nx-os-device"
 )
 
 // Client sends commands to a Cisco device
 type Client struct {
-	conn       *connector.SSHConnection
+	ctx        context.Context
+	conn       connector.Connection
 	Debug      bool
 	OSType     string
 	interfaces []string
+	asaContext string
+
+	// outputs are the outputs of the show commands run by the client, so each command is only run once per scrape
+	outputs map[string]string
+
+	// Host is the device the client sends commands to, which is the key of the command cache
+	Host string
+
+	// Module is the module the device is scraped with (empty for none), the command cache keeps its outputs apart
+	Module string
+
+	// Commands maps commands to the ones sent to the device instead
+	Commands map[string]string
+
+	// CommandCache keeps the output of slow-changing commands across scrapes, nil if outputs are only kept by the client
+	CommandCache *CommandCache
+
+	// Netconf is the NETCONF session to the device, nil if the device is not configured to use NETCONF
+	Netconf *connector.NetconfSession
+
+	// NXAPI is the connection to the NX-API of the device, nil if the device is not configured to use NX-API
+	NXAPI *connector.NXAPIConnection
+
+	// Restconf is the client for the RESTCONF API of the device, nil if the device is not configured to use RESTCONF
+	Restconf *connector.RestconfClient
+
+	// Telemetry is the data streamed by the device, nil if the device is not configured to use telemetry
+	Telemetry *telemetry.Node
+
+	// Info is the information about the device found by Identify
+	Info *DeviceInfo
+
+	// GNMI is the gNMI session to the device, nil if the device is not configured to use gNMI
+	GNMI *connector.GNMISession
 }
 
-// NewClient creates a new client connection
-func NewClient(ssh *connector.SSHConnection, debug bool) *Client {
-	rpc := &Client{conn: ssh, Debug: debug}
+// NewClient creates a new client connection. Commands are canceled when ctx is done.
+func NewClient(ctx context.Context, conn connector.Connection, debug bool) *Client {
+	rpc := &Client{ctx: ctx, conn: conn, Debug: debug, outputs: make(map[string]string)}
 
 	return rpc
 }
 
-// Identify tries to identify the OS running on a Cisco device
+// Identify tries to identify the OS running on a Cisco device and sets Info.
+// If Info is already set (e.g. from a cache) only its OS type is used.
 func (c *Client) Identify() error {
+	if c.Info != nil {
+		c.OSType = c.Info.OSType
+		return nil
+	}
+
+	if c.identifyNetconf() || c.identifyTelemetry() || c.identifyGNMI() {
+		c.Info = &DeviceInfo{OSType: c.OSType, IdentifiedAt: time.Now()}
+		return nil
+	}
+
+	if c.Restconf != nil {
+		// RESTCONF is only supported for IOS XE
+		c.OSType = IOSXE
+		c.Info = &DeviceInfo{OSType: c.OSType, IdentifiedAt: time.Now()}
+		return nil
+	}
+
 	output, err := c.RunCommand("show version")
 	if err != nil {
 		return err
 	}
 	switch {
+	case c.NXAPI != nil:
+		// NX-API is only available on NX-OS
+		c.OSType = NXOS
+	case strings.Contains(output, "Adaptive Security Appliance"):
+		c.OSType = ASA
+	case strings.Contains(output, "Threat Defense"):
+		// the CLI of FTD passes the show commands to the ASA engine
+		c.OSType = ASA
+	case strings.Contains(output, "IOS XR"):
+		c.OSType = IOSXR
 	case strings.Contains(output, "IOS XE"):
 		c.OSType = IOSXE
 	case strings.Contains(output, "IOS-XE"):
@@ -51,19 +125,113 @@ func (c *Client) Identify() error {
 		return errors.New("Unknown OS")
 	}
 	if c.Debug {
-		log.Printf("Host %s identified as: %s\n", c.conn.Host, c.OSType)
+		log.Printf("Host %s identified as: %s\n", c.conn, c.OSType)
 	}
+
+	c.Info = ParseDeviceInfo(c.OSType, output)
+
 	return nil
 }
 
+// identifyNetconf tries to identify the OS by the capabilities announced by the NETCONF server
+func (c *Client) identifyNetconf() bool {
+	if c.Netconf == nil {
+		return false
+	}
+
+	switch {
+	case c.Netconf.HasCapability(iosxeNativeCapability):
+		c.OSType = IOSXE
+	case c.Netconf.HasCapability(nxosDeviceCapability):
+		c.OSType = NXOS
+	default:
+		return false
+	}
+
+	if c.Debug {
+		log.Printf("Host %s identified as: %s\n", c.Netconf, c.OSType)
+	}
+	return true
+}
+
+// HasNetconfCapability checks if the device supports NETCONF and announced the capability
+func (c *Client) HasNetconfCapability(capability string) bool {
+	return c.Netconf != nil && c.Netconf.HasCapability(capability)
+}
+
+// NetconfGet retrieves the state data matching the subtree filter using NETCONF and decodes it into v.
+// The root element of v is the data element of the reply.
+func (c *Client) NetconfGet(filter string, v interface{}) error {
+	if c.Netconf == nil {
+		return errors.New("NETCONF is not enabled for the device")
+	}
+	if c.Debug {
+		log.Printf("Running NETCONF get on %s: %s\n", c.Netconf, filter)
+	}
+
+	data, err := c.Netconf.Get(c.ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	return xml.Unmarshal(append(append([]byte("<data>"), data...), "</data>"...), v)
+}
+
+// Context returns the context commands of the client are run with
+func (c *Client) Context() context.Context {
+	return c.ctx
+}
+
 // RunCommand runs a command on a Cisco device
 func (c *Client) RunCommand(cmd string) (string, error) {
+	return c.RunCommandContext(c.ctx, cmd)
+}
+
+// RunCommandContext runs a command on a Cisco device, canceled when ctx is done.
+// Show commands already run in this scrape or kept in the command cache are answered from the cache.
+func (c *Client) RunCommandContext(ctx context.Context, cmd string) (string, error) {
+	if replacement, found := c.Commands[cmd]; found {
+		cmd = replacement
+	}
+
+	key := c.commandCacheKey(cmd)
+	if key != "" {
+		if output, found := c.cachedOutput(key, cmd); found {
+			if c.Debug {
+				log.Printf("Using cached output on %s: %s\n", c.conn, cmd)
+			}
+			return output, nil
+		}
+	}
+
+	if c.Debug {
+		log.Printf("Running command on %s: %s\n", c.conn, cmd)
+	}
+	output, err := c.conn.RunCommand(ctx, fmt.Sprintf("%s", cmd))
+	if err != nil {
+		if c.Debug {
+			log.Printf("Command on %s failed: %s\n", c.conn, err)
+		}
+		return "", err
+	}
+
+	if key != "" {
+		c.storeOutput(key, cmd, output)
+	}
+
+	return output, nil
+}
+
+// RunCommandExpect runs a command on a Cisco device which ends at a different prompt than usual
+func (c *Client) RunCommandExpect(cmd string, prompt *regexp.Regexp) (string, error) {
 	if c.Debug {
-		log.Printf("Running command on %s: %s\n", c.conn.Host, cmd)
+		log.Printf("Running command on %s: %s\n", c.conn, cmd)
 	}
-	output, err := c.conn.RunCommand(fmt.Sprintf("%s", cmd))
+	output, err := c.conn.RunCommandExpect(c.ctx, cmd, prompt)
 	if err != nil {
-		println(err.Error())
+		if c.Debug {
+			log.Printf("Command on %s failed: %s\n", c.conn, err)
+		}
 		return "", err
 	}
 
@@ -72,7 +240,7 @@ func (c *Client) RunCommand(cmd string) (string, error) {
 
 // Runs command to show interfaces and returns list of interface names
 func (c *Client) GetInterfaceNames(includeVirtual bool) ([]string, error) {
-	if c.OSType != IOSXE && c.OSType != NXOS && c.OSType != IOS {
+	if c.OSType != IOSXE && c.OSType != NXOS && c.OSType != IOS && c.OSType != IOSXR {
 		return nil, errors.New("'show interfaces stats' is not implemented for " + c.OSType)
 	}
 	var items []string
@@ -99,6 +267,10 @@ IFACE:
 }
 
 func (c *Client) PopulateInterfaces() error {
+	if c.OSType == IOSXR {
+		return c.populateInterfacesIOSXR()
+	}
+
 	var items []string
 	out, err := c.RunCommand("show interfaces stats")
 	if err != nil {
@@ -116,3 +288,23 @@ func (c *Client) PopulateInterfaces() error {
 	c.interfaces = items
 	return nil
 }
+
+// populateInterfacesIOSXR reads the interface names from the IPv4 interface list, IOS XR has no interface stats command
+func (c *Client) populateInterfacesIOSXR() error {
+	out, err := c.RunCommand("show ipv4 interface brief")
+	if err != nil {
+		return err
+	}
+
+	var items []string
+	deviceNameRegexp := regexp.MustCompile(`^(\S+)\s+\S+\s+(?:Up|Down|Shutdown)\s+(?:Up|Down)\s+`)
+	for _, line := range strings.Split(out, "\n") {
+		matches := deviceNameRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		items = append(items, matches[1])
+	}
+	c.interfaces = items
+	return nil
+}
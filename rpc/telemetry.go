@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+)
+
+// HasTelemetryPath checks if the device streams telemetry data for the encoding path
+func (c *Client) HasTelemetryPath(path string) bool {
+	return c.Telemetry != nil && c.Telemetry.HasPath(path)
+}
+
+// TelemetryRows decodes the rows of the latest telemetry data of the encoding path into the slice v.
+// Keys and content of a row are merged, so the fields are named like the leaves of the model (or the keys of NX-API output).
+func (c *Client) TelemetryRows(path string, v interface{}) error {
+	if c.Telemetry == nil {
+		return errors.New("telemetry is not enabled for the device")
+	}
+	if c.Debug {
+		log.Printf("Reading telemetry of %s: %s\n", c.Telemetry, path)
+	}
+
+	b, err := json.Marshal(c.Telemetry.Rows(path))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// identifyTelemetry tries to identify the OS by the encoding paths of the telemetry data
+func (c *Client) identifyTelemetry() bool {
+	if c.Telemetry == nil {
+		return false
+	}
+
+	for _, p := range c.Telemetry.Paths() {
+		switch {
+		case strings.HasPrefix(p, "Cisco-IOS-XE-"):
+			c.OSType = IOSXE
+		case strings.HasPrefix(p, "Cisco-IOS-XR-"):
+			c.OSType = IOSXR
+		case strings.HasPrefix(p, "show ") || strings.HasPrefix(p, "sys/"):
+			// NX-API and DME data sources of NX-OS
+			c.OSType = NXOS
+		default:
+			continue
+		}
+
+		if c.Debug {
+			log.Printf("Host %s identified as: %s\n", c.Telemetry, c.OSType)
+		}
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package telemetry
+
+import (
+	"sync"
+	"time"
+)
+
+// Row is an entry of the data sent for an encoding path. Keys and content of the entry are merged,
+// nested containers and lists are maps and slices.
+type Row map[string]interface{}
+
+// Cache keeps the latest data received per device and encoding path
+type Cache struct {
+	mu    sync.Mutex
+	nodes map[string]map[string]*pathData
+}
+
+type pathData struct {
+	collectionID uint64
+	rows         []Row
+	updated      time.Time
+}
+
+// NewCache creates an empty cache
+func NewCache() *Cache {
+	return &Cache{nodes: make(map[string]map[string]*pathData)}
+}
+
+// update stores the rows of a message. Rows of the same collection are added to the ones received before,
+// a new collection (or a message without collection ID) replaces them.
+func (c *Cache) update(node, path string, collectionID uint64, rows []Row) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paths, found := c.nodes[node]
+	if !found {
+		paths = make(map[string]*pathData)
+		c.nodes[node] = paths
+	}
+
+	d, found := paths[path]
+	if !found || d.collectionID != collectionID || collectionID == 0 {
+		d = &pathData{collectionID: collectionID}
+		paths[path] = d
+	}
+
+	d.rows = append(d.rows, rows...)
+	d.updated = time.Now()
+}
+
+// Node returns the data received from node within maxAge, nil if there is none
+func (c *Cache) Node(name string, maxAge time.Duration) *Node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := &Node{
+		Name:  name,
+		paths: make(map[string][]Row),
+	}
+	for path, d := range c.nodes[name] {
+		if time.Since(d.updated) <= maxAge {
+			n.paths[path] = d.rows
+		}
+	}
+
+	if len(n.paths) == 0 {
+		return nil
+	}
+
+	return n
+}
+
+// Node is a snapshot of the data received from a device
+type Node struct {
+	Name  string
+	paths map[string][]Row
+}
+
+// HasPath checks if data of the encoding path was received
+func (n *Node) HasPath(path string) bool {
+	_, found := n.paths[path]
+	return found
+}
+
+// Paths returns the encoding paths data was received for
+func (n *Node) Paths() []string {
+	paths := make([]string, 0, len(n.paths))
+	for p := range n.paths {
+		paths = append(paths, p)
+	}
+
+	return paths
+}
+
+// Rows returns the rows of the latest collection of the encoding path
+func (n *Node) Rows(path string) []Row {
+	return n.paths[path]
+}
+
+func (n *Node) String() string {
+	return n.Name
+}
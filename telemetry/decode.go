@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"github.com/lwlcom/cisco_exporter/telemetry/mdt"
+)
+
+// rowsFromKV converts the rows of the self-describing (key value) encoding.
+// Each row consists of the fields keys and content, which are merged into one row.
+func rowsFromKV(fields []*mdt.TelemetryField) []Row {
+	rows := make([]Row, 0, len(fields))
+	for _, f := range fields {
+		row := make(Row)
+		for _, part := range f.Fields {
+			switch part.Name {
+			case "keys", "content":
+				addFields(row, part.Fields)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// addFields adds the fields to m. Fields occurring more than once (entries of lists) are added as slice.
+func addFields(m map[string]interface{}, fields []*mdt.TelemetryField) {
+	for _, f := range fields {
+		v := fieldValue(f)
+
+		existing, found := m[f.Name]
+		if !found {
+			m[f.Name] = v
+			continue
+		}
+
+		if l, isList := existing.([]interface{}); isList {
+			m[f.Name] = append(l, v)
+		} else {
+			m[f.Name] = []interface{}{existing, v}
+		}
+	}
+}
+
+func fieldValue(f *mdt.TelemetryField) interface{} {
+	if len(f.Fields) > 0 {
+		m := make(map[string]interface{})
+		addFields(m, f.Fields)
+		return m
+	}
+
+	switch v := f.ValueByType.(type) {
+	case *mdt.TelemetryField_BytesValue:
+		return v.BytesValue
+	case *mdt.TelemetryField_StringValue:
+		return v.StringValue
+	case *mdt.TelemetryField_BoolValue:
+		return v.BoolValue
+	case *mdt.TelemetryField_Uint32Value:
+		return v.Uint32Value
+	case *mdt.TelemetryField_Uint64Value:
+		return v.Uint64Value
+	case *mdt.TelemetryField_Sint32Value:
+		return v.Sint32Value
+	case *mdt.TelemetryField_Sint64Value:
+		return v.Sint64Value
+	case *mdt.TelemetryField_DoubleValue:
+		return v.DoubleValue
+	case *mdt.TelemetryField_FloatValue:
+		return v.FloatValue
+	}
+
+	return nil
+}
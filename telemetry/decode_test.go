@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lwlcom/cisco_exporter/telemetry/mdt"
+)
+
+func stringField(name, v string) *mdt.TelemetryField {
+	return &mdt.TelemetryField{Name: name, ValueByType: &mdt.TelemetryField_StringValue{StringValue: v}}
+}
+
+func uint64Field(name string, v uint64) *mdt.TelemetryField {
+	return &mdt.TelemetryField{Name: name, ValueByType: &mdt.TelemetryField_Uint64Value{Uint64Value: v}}
+}
+
+func TestRowsFromKV(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []*mdt.TelemetryField
+		want   []Row
+	}{
+		{
+			name: "keys and content are merged",
+			fields: []*mdt.TelemetryField{{
+				Fields: []*mdt.TelemetryField{
+					{Name: "keys", Fields: []*mdt.TelemetryField{stringField("name", "Gi1")}},
+					{Name: "content", Fields: []*mdt.TelemetryField{
+						stringField("oper-status", "if-oper-state-ready"),
+						{Name: "statistics", Fields: []*mdt.TelemetryField{uint64Field("in-octets", 5)}},
+					}},
+				},
+			}},
+			want: []Row{{
+				"name":        "Gi1",
+				"oper-status": "if-oper-state-ready",
+				"statistics":  map[string]interface{}{"in-octets": uint64(5)},
+			}},
+		},
+		{
+			name: "repeated fields are lists",
+			fields: []*mdt.TelemetryField{{
+				Fields: []*mdt.TelemetryField{
+					{Name: "content", Fields: []*mdt.TelemetryField{
+						{Name: "ROW", Fields: []*mdt.TelemetryField{stringField("interface", "Eth1/1")}},
+						{Name: "ROW", Fields: []*mdt.TelemetryField{stringField("interface", "Eth1/2")}},
+						{Name: "ROW", Fields: []*mdt.TelemetryField{stringField("interface", "Eth1/3")}},
+					}},
+				},
+			}},
+			want: []Row{{
+				"ROW": []interface{}{
+					map[string]interface{}{"interface": "Eth1/1"},
+					map[string]interface{}{"interface": "Eth1/2"},
+					map[string]interface{}{"interface": "Eth1/3"},
+				},
+			}},
+		},
+		{
+			name: "fields besides keys and content are ignored",
+			fields: []*mdt.TelemetryField{{
+				Fields: []*mdt.TelemetryField{
+					stringField("timestamp", "1"),
+					{Name: "content", Fields: []*mdt.TelemetryField{
+						{Name: "enabled", ValueByType: &mdt.TelemetryField_BoolValue{BoolValue: true}},
+						{Name: "load", ValueByType: &mdt.TelemetryField_DoubleValue{DoubleValue: 0.5}},
+						{Name: "delta", ValueByType: &mdt.TelemetryField_Sint64Value{Sint64Value: -3}},
+					}},
+				},
+			}},
+			want: []Row{{"enabled": true, "load": 0.5, "delta": int64(-3)}},
+		},
+		{
+			name:   "no rows",
+			fields: nil,
+			want:   []Row{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := rowsFromKV(test.fields)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
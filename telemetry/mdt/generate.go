@@ -0,0 +1,4 @@
+// Package mdt contains the messages and the gRPC dial-out service of Cisco model-driven telemetry
+package mdt
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative telemetry.proto mdt_grpc_dialout.proto
@@ -0,0 +1,187 @@
+// gRPC dial-out service of Cisco model-driven telemetry, see
+// https://github.com/cisco-ie/bigmuddy-network-telemetry-proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: mdt_grpc_dialout.proto
+
+package mdt
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MdtDialoutArgs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ReqId int64 `protobuf:"varint,1,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	// encoded Telemetry message
+	Data   []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Errors string `protobuf:"bytes,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	// NX-OS splits large messages, totalSize is the size of the complete message
+	TotalSize int32 `protobuf:"varint,4,opt,name=totalSize,proto3" json:"totalSize,omitempty"`
+}
+
+func (x *MdtDialoutArgs) Reset() {
+	*x = MdtDialoutArgs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mdt_grpc_dialout_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MdtDialoutArgs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MdtDialoutArgs) ProtoMessage() {}
+
+func (x *MdtDialoutArgs) ProtoReflect() protoreflect.Message {
+	mi := &file_mdt_grpc_dialout_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MdtDialoutArgs.ProtoReflect.Descriptor instead.
+func (*MdtDialoutArgs) Descriptor() ([]byte, []int) {
+	return file_mdt_grpc_dialout_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MdtDialoutArgs) GetReqId() int64 {
+	if x != nil {
+		return x.ReqId
+	}
+	return 0
+}
+
+func (x *MdtDialoutArgs) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *MdtDialoutArgs) GetErrors() string {
+	if x != nil {
+		return x.Errors
+	}
+	return ""
+}
+
+func (x *MdtDialoutArgs) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+var File_mdt_grpc_dialout_proto protoreflect.FileDescriptor
+
+var file_mdt_grpc_dialout_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x6d, 0x64, 0x74, 0x5f, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x64, 0x69, 0x61, 0x6c, 0x6f,
+	0x75, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x6d, 0x64, 0x74, 0x5f, 0x64, 0x69,
+	0x61, 0x6c, 0x6f, 0x75, 0x74, 0x22, 0x70, 0x0a, 0x0e, 0x4d, 0x64, 0x74, 0x44, 0x69, 0x61, 0x6c,
+	0x6f, 0x75, 0x74, 0x41, 0x72, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x52, 0x65, 0x71, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x52, 0x65, 0x71, 0x49, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x32, 0x5e, 0x0a, 0x0e, 0x67, 0x52, 0x50, 0x43, 0x4d,
+	0x64, 0x74, 0x44, 0x69, 0x61, 0x6c, 0x6f, 0x75, 0x74, 0x12, 0x4c, 0x0a, 0x0a, 0x4d, 0x64, 0x74,
+	0x44, 0x69, 0x61, 0x6c, 0x6f, 0x75, 0x74, 0x12, 0x1b, 0x2e, 0x6d, 0x64, 0x74, 0x5f, 0x64, 0x69,
+	0x61, 0x6c, 0x6f, 0x75, 0x74, 0x2e, 0x4d, 0x64, 0x74, 0x44, 0x69, 0x61, 0x6c, 0x6f, 0x75, 0x74,
+	0x41, 0x72, 0x67, 0x73, 0x1a, 0x1b, 0x2e, 0x6d, 0x64, 0x74, 0x5f, 0x64, 0x69, 0x61, 0x6c, 0x6f,
+	0x75, 0x74, 0x2e, 0x4d, 0x64, 0x74, 0x44, 0x69, 0x61, 0x6c, 0x6f, 0x75, 0x74, 0x41, 0x72, 0x67,
+	0x73, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x77, 0x6c, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x69, 0x73,
+	0x63, 0x6f, 0x5f, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x72, 0x2f, 0x74, 0x65, 0x6c, 0x65,
+	0x6d, 0x65, 0x74, 0x72, 0x79, 0x2f, 0x6d, 0x64, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_mdt_grpc_dialout_proto_rawDescOnce sync.Once
+	file_mdt_grpc_dialout_proto_rawDescData = file_mdt_grpc_dialout_proto_rawDesc
+)
+
+func file_mdt_grpc_dialout_proto_rawDescGZIP() []byte {
+	file_mdt_grpc_dialout_proto_rawDescOnce.Do(func() {
+		file_mdt_grpc_dialout_proto_rawDescData = protoimpl.X.CompressGZIP(file_mdt_grpc_dialout_proto_rawDescData)
+	})
+	return file_mdt_grpc_dialout_proto_rawDescData
+}
+
+var file_mdt_grpc_dialout_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_mdt_grpc_dialout_proto_goTypes = []interface{}{
+	(*MdtDialoutArgs)(nil), // 0: mdt_dialout.MdtDialoutArgs
+}
+var file_mdt_grpc_dialout_proto_depIdxs = []int32{
+	0, // 0: mdt_dialout.gRPCMdtDialout.MdtDialout:input_type -> mdt_dialout.MdtDialoutArgs
+	0, // 1: mdt_dialout.gRPCMdtDialout.MdtDialout:output_type -> mdt_dialout.MdtDialoutArgs
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_mdt_grpc_dialout_proto_init() }
+func file_mdt_grpc_dialout_proto_init() {
+	if File_mdt_grpc_dialout_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_mdt_grpc_dialout_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MdtDialoutArgs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_mdt_grpc_dialout_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mdt_grpc_dialout_proto_goTypes,
+		DependencyIndexes: file_mdt_grpc_dialout_proto_depIdxs,
+		MessageInfos:      file_mdt_grpc_dialout_proto_msgTypes,
+	}.Build()
+	File_mdt_grpc_dialout_proto = out.File
+	file_mdt_grpc_dialout_proto_rawDesc = nil
+	file_mdt_grpc_dialout_proto_goTypes = nil
+	file_mdt_grpc_dialout_proto_depIdxs = nil
+}
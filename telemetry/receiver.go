@@ -0,0 +1,133 @@
+// Package telemetry receives model-driven telemetry streamed by devices (gRPC dial-out)
+// and keeps the latest data per device for the collectors.
+package telemetry
+
+import (
+	"io"
+	"net"
+
+	"github.com/lwlcom/cisco_exporter/telemetry/mdt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// Receiver accepts gRPC dial-out connections of devices and stores the telemetry sent in a cache
+type Receiver struct {
+	mdt.UnimplementedGRPCMdtDialoutServer
+
+	cache  *Cache
+	server *grpc.Server
+	debug  bool
+}
+
+// NewReceiver creates a receiver storing the telemetry in cache.
+// If certFile and keyFile are set devices have to connect using TLS.
+func NewReceiver(cache *Cache, certFile, keyFile string, debug bool) (*Receiver, error) {
+	var opts []grpc.ServerOption
+	if certFile != "" || keyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load telemetry certificate")
+		}
+
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	r := &Receiver{
+		cache:  cache,
+		server: grpc.NewServer(opts...),
+		debug:  debug,
+	}
+	mdt.RegisterGRPCMdtDialoutServer(r.server, r)
+
+	return r, nil
+}
+
+// Serve accepts connections on l until Stop is called
+func (r *Receiver) Serve(l net.Listener) error {
+	return r.server.Serve(l)
+}
+
+// Stop closes the listener and all streams
+func (r *Receiver) Stop() {
+	r.server.Stop()
+}
+
+// MdtDialout receives the messages of a device until it closes the stream
+func (r *Receiver) MdtDialout(stream mdt.GRPCMdtDialout_MdtDialoutServer) error {
+	addr := "unknown"
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		addr = p.Addr.String()
+	}
+	log.Infof("%s: telemetry stream opened", addr)
+
+	// NX-OS splits messages exceeding the maximum size
+	var buf []byte
+	for {
+		args, err := stream.Recv()
+		if err == io.EOF {
+			log.Infof("%s: telemetry stream closed", addr)
+			return nil
+		}
+		if err != nil {
+			log.Errorf("%s: telemetry stream failed: %v", addr, err)
+			return err
+		}
+
+		if args.Errors != "" {
+			log.Errorf("%s: device reported telemetry error: %s", addr, args.Errors)
+		}
+
+		data := args.Data
+		if args.TotalSize > 0 {
+			buf = append(buf, data...)
+			if len(buf) < int(args.TotalSize) {
+				continue
+			}
+
+			data, buf = buf, nil
+		}
+
+		if len(data) == 0 {
+			continue
+		}
+
+		err = r.handleMessage(data)
+		if err != nil {
+			log.Errorf("%s: %v", addr, err)
+		}
+	}
+}
+
+func (r *Receiver) handleMessage(data []byte) error {
+	msg := &mdt.Telemetry{}
+	err := proto.Unmarshal(data, msg)
+	if err != nil {
+		return errors.Wrap(err, "could not decode telemetry message")
+	}
+
+	node := msg.GetNodeIdStr()
+	if node == "" {
+		return errors.Errorf("telemetry message for %s without node ID", msg.EncodingPath)
+	}
+
+	if len(msg.DataGpbkv) == 0 {
+		if msg.DataGpb != nil && len(msg.DataGpb.Row) > 0 {
+			return errors.Errorf("%s: compact GPB encoding of %s is not supported, use self-describing GPB (kvGPB)", node, msg.EncodingPath)
+		}
+
+		return nil
+	}
+
+	if r.debug {
+		log.Infof("Received telemetry from %s: %s (%d rows)", node, msg.EncodingPath, len(msg.DataGpbkv))
+	}
+
+	r.cache.update(node, msg.EncodingPath, msg.CollectionId, rowsFromKV(msg.DataGpbkv))
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lwlcom/cisco_exporter/telemetry/mdt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// The messages in testdata were captured from the gRPC dial-out streams of fakecisco imitating an IOS XE (csr1)
+// and an NX-OS (nx1) device.
+
+func readMessage(t *testing.T, name string) []byte {
+	t.Helper()
+
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestHandleMessageIOSXE(t *testing.T) {
+	cache := NewCache()
+	r := &Receiver{cache: cache}
+
+	err := r.handleMessage(readMessage(t, "iosxe_interfaces.kvgpb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := cache.Node("csr1", time.Minute)
+	if node == nil {
+		t.Fatal("no data cached for csr1")
+	}
+
+	rows := node.Rows("Cisco-IOS-XE-interfaces-oper:interfaces/interface")
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	row := rows[0]
+	if row["name"] != "TenGigabitEthernet1/0/1" || row["oper-status"] != "if-oper-state-ready" || row["description"] != "uplink" {
+		t.Errorf("got %v", row)
+	}
+	if row["speed"] != uint64(10000000000) {
+		t.Errorf("got speed %#v, want 10000000000", row["speed"])
+	}
+	stats, _ := row["statistics"].(map[string]interface{})
+	if stats["in-octets"] != uint64(98765432) || stats["out-octets"] != uint64(87654321) {
+		t.Errorf("got statistics %v", stats)
+	}
+
+	if rows[1]["name"] != "TenGigabitEthernet1/0/2" || rows[1]["admin-status"] != "if-state-down" {
+		t.Errorf("got %v", rows[1])
+	}
+}
+
+func TestHandleMessageNXOS(t *testing.T) {
+	cache := NewCache()
+	r := &Receiver{cache: cache}
+
+	err := r.handleMessage(readMessage(t, "nxos_show_interface.kvgpb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := cache.Node("nx1", time.Minute).Rows("show interface")
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+
+	table, _ := rows[0]["TABLE_interface"].(map[string]interface{})
+	entries, _ := table["ROW_interface"].([]interface{})
+	if len(entries) != 3 {
+		t.Fatalf("got %d interfaces, want 3: %v", len(entries), rows[0])
+	}
+
+	want := []struct {
+		name    string
+		state   string
+		inBytes interface{}
+	}{
+		{name: "Ethernet1/1", state: "up", inBytes: uint64(98765432)},
+		{name: "Ethernet1/2", state: "down", inBytes: uint64(0)},
+	}
+	for i, w := range want {
+		e := entries[i].(map[string]interface{})
+		if e["interface"] != w.name || e["state"] != w.state || e["eth_inbytes"] != w.inBytes {
+			t.Errorf("got %v, want %s %s with %v bytes in", e, w.name, w.state, w.inBytes)
+		}
+	}
+}
+
+func TestHandleMessageCompactGPB(t *testing.T) {
+	msg := &mdt.Telemetry{
+		NodeId:       &mdt.Telemetry_NodeIdStr{NodeIdStr: "csr1"},
+		EncodingPath: "Cisco-IOS-XE-interfaces-oper:interfaces/interface",
+		DataGpb:      &mdt.TelemetryGPBTable{Row: []*mdt.TelemetryRowGPB{{Keys: []byte{0x0a, 0x01, 0x41}, Content: []byte{0x0a, 0x01, 0x42}}}},
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := NewCache()
+	r := &Receiver{cache: cache}
+	err = r.handleMessage(data)
+	if err == nil || !strings.Contains(err.Error(), "compact GPB") {
+		t.Fatalf("got %v, want compact GPB to be rejected", err)
+	}
+
+	if cache.Node("csr1", time.Minute) != nil {
+		t.Error("no data must be cached for a compact GPB message")
+	}
+}
+
+func TestHandleMessageWithoutNodeID(t *testing.T) {
+	data, err := proto.Marshal(&mdt.Telemetry{EncodingPath: "show interface"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Receiver{cache: NewCache()}
+	if err := r.handleMessage(data); err == nil {
+		t.Error("expected an error for a message without node ID")
+	}
+}
+
+func TestHandleMessageInvalid(t *testing.T) {
+	r := &Receiver{cache: NewCache()}
+	if err := r.handleMessage([]byte{0xff, 0xff, 0xff}); err == nil {
+		t.Error("expected an error for data which is no telemetry message")
+	}
+}
+
+// TestMdtDialout streams the captured messages to a receiver like a device does, NX-OS splitting messages in chunks
+func TestMdtDialout(t *testing.T) {
+	cache := NewCache()
+	r, err := NewReceiver(cache, "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	go r.Serve(lis)
+	defer r.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stream, err := mdt.NewGRPCMdtDialoutClient(conn).MdtDialout(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = stream.Send(&mdt.MdtDialoutArgs{ReqId: 1, Data: readMessage(t, "iosxe_interfaces.kvgpb")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nxos := readMessage(t, "nxos_show_interface.kvgpb")
+	half := len(nxos) / 2
+	for _, chunk := range [][]byte{nxos[:half], nxos[half:]} {
+		err = stream.Send(&mdt.MdtDialoutArgs{ReqId: 2, Data: chunk, TotalSize: int32(len(nxos))})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = stream.CloseSend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the receiver closes the stream after it handled all messages
+	stream.Recv()
+
+	if n := cache.Node("csr1", time.Minute); n == nil || !n.HasPath("Cisco-IOS-XE-interfaces-oper:interfaces/interface") {
+		t.Error("no interfaces received from csr1")
+	}
+	if n := cache.Node("nx1", time.Minute); n == nil || len(n.Rows("show interface")) != 1 {
+		t.Error("the chunked message of nx1 was not received")
+	}
+}
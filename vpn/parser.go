@@ -0,0 +1,60 @@
+package vpn
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+	"github.com/lwlcom/cisco_exporter/util"
+)
+
+// Parse parses cli output of show vpn-sessiondb summary. Only the VPN types are returned, not the protocols listed below them.
+func (c *vpnCollector) Parse(ostype string, output string) (*Summary, error) {
+	if ostype != rpc.ASA {
+		return nil, errors.New("'show vpn-sessiondb summary' is not implemented for " + ostype)
+	}
+	//                                Active : Cumulative : Peak Concur : Inactive
+	// AnyConnect Client            :     45 :       1234 :          80 :        3
+	//   SSL/TLS/DTLS               :     45 :       1234 :          80 :        3
+	// Site-to-Site VPN             :     12 :        345 :          14
+	// Device Total VPN Capacity    :    750
+	// Device Load                  :      8%
+	sessionsRegexp := regexp.MustCompile(`^(\S.*?)\s+:\s+(\d+) :\s+(\d+) :\s+(\d+)(?: :\s+(\d+))?\s*$`)
+	capacityRegexp := regexp.MustCompile(`^Device Total VPN Capacity\s+:\s+(\d+)`)
+	loadRegexp := regexp.MustCompile(`^Device Load\s+:\s+(\d+)%`)
+	tunnelsRegexp := regexp.MustCompile(`^Tunnels Summary`)
+
+	s := &Summary{}
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		if tunnelsRegexp.MatchString(line) {
+			// the tunnels are listed in the same format as the sessions
+			break
+		}
+		if matches := sessionsRegexp.FindStringSubmatch(line); matches != nil {
+			sessions := Sessions{
+				Type:       matches[1],
+				Active:     util.Str2float64(matches[2]),
+				Cumulative: util.Str2float64(matches[3]),
+				Peak:       util.Str2float64(matches[4]),
+				Inactive:   -1,
+			}
+			if matches[5] != "" {
+				sessions.Inactive = util.Str2float64(matches[5])
+			}
+			s.Sessions = append(s.Sessions, sessions)
+			found = true
+		} else if matches := capacityRegexp.FindStringSubmatch(line); matches != nil {
+			s.Capacity = util.Str2float64(matches[1])
+			found = true
+		} else if matches := loadRegexp.FindStringSubmatch(line); matches != nil {
+			s.Load = util.Str2float64(matches[1])
+		}
+	}
+	if !found {
+		return nil, errors.New("VPN session summary not found")
+	}
+
+	return s, nil
+}
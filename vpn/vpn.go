@@ -0,0 +1,17 @@
+package vpn
+
+// Summary is the VPN session summary of a context
+type Summary struct {
+	Sessions []Sessions
+	Capacity float64
+	Load     float64
+}
+
+// Sessions are the session counts of a VPN type. Inactive is -1 if not reported for the type.
+type Sessions struct {
+	Type       string
+	Active     float64
+	Cumulative float64
+	Peak       float64
+	Inactive   float64
+}
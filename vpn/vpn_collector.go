@@ -0,0 +1,92 @@
+package vpn
+
+import (
+	"log"
+
+	"github.com/lwlcom/cisco_exporter/rpc"
+
+	"github.com/lwlcom/cisco_exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prefix string = "cisco_vpn_"
+
+var (
+	activeDesc     *prometheus.Desc
+	cumulativeDesc *prometheus.Desc
+	peakDesc       *prometheus.Desc
+	inactiveDesc   *prometheus.Desc
+	capacityDesc   *prometheus.Desc
+	loadDesc       *prometheus.Desc
+)
+
+func init() {
+	l := []string{"target", "context"}
+	capacityDesc = prometheus.NewDesc(prefix+"capacity", "Total VPN session capacity of the device", l, nil)
+	loadDesc = prometheus.NewDesc(prefix+"load_percent", "VPN load of the device in percent", l, nil)
+
+	l = append(l, "type")
+	activeDesc = prometheus.NewDesc(prefix+"sessions_active", "Active VPN sessions", l, nil)
+	cumulativeDesc = prometheus.NewDesc(prefix+"sessions_total", "Cumulative number of VPN sessions", l, nil)
+	peakDesc = prometheus.NewDesc(prefix+"sessions_peak", "Peak number of concurrent VPN sessions", l, nil)
+	inactiveDesc = prometheus.NewDesc(prefix+"sessions_inactive", "Inactive VPN sessions", l, nil)
+}
+
+type vpnCollector struct {
+}
+
+// NewCollector creates a new collector
+func NewCollector() collector.RPCCollector {
+	return &vpnCollector{}
+}
+
+// Name returns the name of the collector
+func (*vpnCollector) Name() string {
+	return "VPN"
+}
+
+// Describe describes the metrics
+func (*vpnCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeDesc
+	ch <- cumulativeDesc
+	ch <- peakDesc
+	ch <- inactiveDesc
+	ch <- capacityDesc
+	ch <- loadDesc
+}
+
+// Collect collects metrics from Cisco. On ASAs in multiple context mode all contexts are walked.
+func (c *vpnCollector) Collect(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	return client.ForEachASAContext(func(context string) error {
+		return c.collectForContext(client, ch, append(labelValues, context))
+	})
+}
+
+func (c *vpnCollector) collectForContext(client *rpc.Client, ch chan<- prometheus.Metric, labelValues []string) error {
+	out, err := client.RunCommand("show vpn-sessiondb summary")
+	if err != nil {
+		return err
+	}
+	s, err := c.Parse(client.OSType, out)
+	if err != nil {
+		if client.Debug {
+			log.Printf("Parse vpn-sessiondb for %s: %s\n", labelValues[0], err.Error())
+		}
+		return nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(capacityDesc, prometheus.GaugeValue, s.Capacity, labelValues...)
+	ch <- prometheus.MustNewConstMetric(loadDesc, prometheus.GaugeValue, s.Load, labelValues...)
+
+	for _, sessions := range s.Sessions {
+		l := append(labelValues, sessions.Type)
+		ch <- prometheus.MustNewConstMetric(activeDesc, prometheus.GaugeValue, sessions.Active, l...)
+		ch <- prometheus.MustNewConstMetric(cumulativeDesc, prometheus.CounterValue, sessions.Cumulative, l...)
+		ch <- prometheus.MustNewConstMetric(peakDesc, prometheus.GaugeValue, sessions.Peak, l...)
+		if sessions.Inactive >= 0 {
+			ch <- prometheus.MustNewConstMetric(inactiveDesc, prometheus.GaugeValue, sessions.Inactive, l...)
+		}
+	}
+
+	return nil
+}